@@ -1,8 +1,12 @@
 package xdg
 
 import (
+	"io"
 	"os"
+	"path/filepath"
 
+	"github.com/adrg/xdg/internal/history"
+	"github.com/adrg/xdg/internal/logfile"
 	"github.com/adrg/xdg/internal/pathutil"
 )
 
@@ -80,3 +84,36 @@ func (bd baseDirectories) searchCacheFile(relPath string) (string, error) {
 func (bd baseDirectories) searchRuntimeFile(relPath string) (string, error) {
 	return pathutil.Search(relPath, pathutil.Unique([]string{bd.runtime, os.TempDir()}))
 }
+
+func (bd baseDirectories) logFile(relPath string, opts *LogOptions) (io.WriteCloser, error) {
+	name, err := bd.stateFile(relPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &defaultLogOptions
+	}
+
+	return logfile.Open(name, *opts)
+}
+
+func (bd baseDirectories) historyFile(relPath string, opts *HistoryOptions) (*History, error) {
+	name, err := bd.stateFile(relPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &defaultHistoryOptions
+	}
+
+	return history.New(name, *opts), nil
+}
+
+func (bd baseDirectories) watchConfigFile(relPath string) (*ConfigWatcher, error) {
+	var paths []string
+	for _, dir := range append([]string{bd.configHome}, bd.config...) {
+		paths = append(paths, filepath.Join(dir, relPath))
+	}
+
+	return newConfigWatcher(paths)
+}
@@ -0,0 +1,155 @@
+package desktop
+
+import (
+	"errors"
+	"io/fs"
+	"iter"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/adrg/xdg"
+)
+
+// All returns an iterator over the desktop entries located in the
+// application directories defined by xdg.ApplicationDirs. The application
+// directories are traversed in order of precedence and entries shadow the
+// entries with the same desktop file ID located in directories with lower
+// precedence. Entries marked as hidden are treated as deleted: they are not
+// returned, but they still shadow the entries with lower precedence.
+//
+// Desktop entry files which cannot be read or parsed are reported as errors
+// and also shadow the entries with lower precedence. The returned entries
+// are not filtered based on their visibility. Use Entry.ShouldShow in order
+// to determine if an entry should be displayed in menus.
+func All() iter.Seq2[*Entry, error] {
+	return func(yield func(*Entry, error) bool) {
+		seen := map[string]bool{}
+		for _, dir := range xdg.ApplicationDirs {
+			paths, err := entryPaths(dir)
+			if err != nil {
+				if !yield(nil, err) {
+					return
+				}
+			}
+
+			for _, path := range paths {
+				id, ok := entryID(dir, path)
+				if !ok || seen[id] {
+					continue
+				}
+				seen[id] = true
+
+				entry, err := ParseFile(path)
+				if err != nil {
+					if !yield(nil, err) {
+						return
+					}
+					continue
+				}
+				if entry.Hidden {
+					continue
+				}
+				entry.ID = id
+
+				if !yield(entry, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// entryPaths returns the paths of the desktop entry files located in the
+// specified directory and its subdirectories, in lexical order. Missing
+// directories are ignored.
+func entryPaths(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, fileExt) {
+			return nil
+		}
+
+		// Include symbolic links to regular files.
+		if !d.Type().IsRegular() {
+			if fi, err := os.Stat(path); err != nil || !fi.Mode().IsRegular() {
+				return nil
+			}
+		}
+
+		paths = append(paths, path)
+		return nil
+	})
+
+	return paths, err
+}
+
+// entryID returns the desktop file ID of the desktop entry file at the
+// specified path, relative to the specified application directory.
+func entryID(dir, path string) (string, bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return strings.ReplaceAll(filepath.ToSlash(rel), "/", "-"), true
+}
+
+// CurrentDesktops returns the names of the current desktop environments,
+// as defined by the colon separated XDG_CURRENT_DESKTOP environment
+// variable (e.g. ubuntu:GNOME).
+func CurrentDesktops() []string {
+	var desktops []string
+	for _, name := range strings.Split(os.Getenv("XDG_CURRENT_DESKTOP"), ":") {
+		if name = strings.TrimSpace(name); name != "" {
+			desktops = append(desktops, name)
+		}
+	}
+
+	return desktops
+}
+
+// ShouldShow returns true if the entry should be displayed in menus, for
+// the current desktop environments returned by CurrentDesktops. Entries are
+// not displayed if they are hidden, if the NoDisplay key is set, if they
+// are excluded by the OnlyShowIn and NotShowIn keys, or if the program
+// specified by the TryExec key cannot be found in the directories defined
+// by the PATH environment variable. The current desktop environments are
+// checked in order and the first one listed by either OnlyShowIn or
+// NotShowIn determines the visibility of the entry.
+func (e *Entry) ShouldShow() bool {
+	if e.Hidden || e.NoDisplay {
+		return false
+	}
+	if !e.showIn(CurrentDesktops()) {
+		return false
+	}
+	if e.TryExec != "" {
+		if _, err := exec.LookPath(e.TryExec); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e *Entry) showIn(desktops []string) bool {
+	for _, name := range desktops {
+		if slices.Contains(e.NotShowIn, name) {
+			return false
+		}
+		if slices.Contains(e.OnlyShowIn, name) {
+			return true
+		}
+	}
+
+	return len(e.OnlyShowIn) == 0
+}
@@ -0,0 +1,337 @@
+package desktop_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adrg/xdg/desktop"
+)
+
+const editorEntry = `[Desktop Entry]
+Type=Application
+Version=1.5
+Name=Text Editor
+Name[fr]=Éditeur de texte
+GenericName=Editor
+Comment=Edit text files\nquickly
+Icon=text-editor
+TryExec=editor
+Exec=editor --new-window %F
+Path=/tmp
+Terminal=false
+Keywords=text;plain;
+Keywords[fr]=texte;
+MimeType=text/plain;text/x-csrc;
+Categories=Utility;TextEditor;
+OnlyShowIn=GNOME;XFCE;
+StartupNotify=true
+StartupWMClass=Editor
+Actions=new-window;missing;
+X-Custom=custom\svalue
+
+[Desktop Action new-window]
+Name=New Window
+Name[fr]=Nouvelle fenêtre
+Exec=editor --new-window
+`
+
+func TestParse(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+
+	entry, err := desktop.Parse(strings.NewReader(editorEntry))
+	require.NoError(t, err)
+	require.Equal(t, desktop.TypeApplication, entry.Type)
+	require.Equal(t, "1.5", entry.Version)
+	require.Equal(t, "Text Editor", entry.Name)
+	require.Equal(t, "Editor", entry.GenericName)
+	require.Equal(t, "Edit text files\nquickly", entry.Comment)
+	require.Equal(t, "text-editor", entry.Icon)
+	require.Equal(t, "editor", entry.TryExec)
+	require.Equal(t, "editor --new-window %F", entry.Exec)
+	require.Equal(t, "/tmp", entry.WorkingDir)
+	require.False(t, entry.Terminal)
+	require.False(t, entry.Hidden)
+	require.False(t, entry.NoDisplay)
+	require.True(t, entry.StartupNotify)
+	require.Equal(t, "Editor", entry.StartupWMClass)
+	require.Equal(t, []string{"text", "plain"}, entry.Keywords)
+	require.Equal(t, []string{"text/plain", "text/x-csrc"}, entry.MimeType)
+	require.Equal(t, []string{"Utility", "TextEditor"}, entry.Categories)
+	require.Equal(t, []string{"GNOME", "XFCE"}, entry.OnlyShowIn)
+	require.Empty(t, entry.NotShowIn)
+	require.Equal(t, []*desktop.Action{{
+		ID:   "new-window",
+		Name: "New Window",
+		Exec: "editor --new-window",
+	}}, entry.Actions)
+
+	value, ok := entry.Value("X-Custom")
+	require.True(t, ok)
+	require.Equal(t, "custom value", value)
+
+	_, ok = entry.Value("X-Missing")
+	require.False(t, ok)
+}
+
+func TestParseLocalized(t *testing.T) {
+	t.Setenv("LC_ALL", "fr_FR.UTF-8")
+
+	entry, err := desktop.Parse(strings.NewReader(editorEntry))
+	require.NoError(t, err)
+	require.Equal(t, "Éditeur de texte", entry.Name)
+	require.Equal(t, "Editor", entry.GenericName)
+	require.Equal(t, []string{"texte"}, entry.Keywords)
+	require.Len(t, entry.Actions, 1)
+	require.Equal(t, "Nouvelle fenêtre", entry.Actions[0].Name)
+
+	value, ok := entry.LocalizedValue("Name")
+	require.True(t, ok)
+	require.Equal(t, "Éditeur de texte", value)
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := desktop.Parse(strings.NewReader("[Other Group]\nName=Other\n"))
+	require.Error(t, err)
+
+	_, err = desktop.Parse(strings.NewReader("[Desktop Entry]\ninvalid line\n"))
+	require.Error(t, err)
+
+	_, err = desktop.ParseFile(filepath.Join(t.TempDir(), "missing.desktop"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestShouldShow(t *testing.T) {
+	executable, err := os.Executable()
+	require.NoError(t, err)
+
+	tests := []struct {
+		entry    string
+		desktops string
+		expected bool
+	}{
+		{entry: "", expected: true},
+		{entry: "Hidden=true", expected: false},
+		{entry: "NoDisplay=true", expected: false},
+		{entry: "OnlyShowIn=GNOME;KDE;", desktops: "", expected: false},
+		{entry: "OnlyShowIn=GNOME;KDE;", desktops: "XFCE", expected: false},
+		{entry: "OnlyShowIn=GNOME;KDE;", desktops: "ubuntu:GNOME", expected: true},
+		{entry: "NotShowIn=GNOME;", desktops: "", expected: true},
+		{entry: "NotShowIn=GNOME;", desktops: "ubuntu:GNOME", expected: false},
+		{entry: "NotShowIn=GNOME;\nOnlyShowIn=ubuntu;", desktops: "ubuntu:GNOME", expected: true},
+		{entry: "NotShowIn=ubuntu;\nOnlyShowIn=GNOME;", desktops: "ubuntu:GNOME", expected: false},
+		{entry: "TryExec=" + executable, expected: true},
+		{entry: "TryExec=" + filepath.Join(t.TempDir(), "missing"), expected: false},
+	}
+
+	for _, test := range tests {
+		t.Setenv("XDG_CURRENT_DESKTOP", test.desktops)
+
+		entry, err := desktop.Parse(strings.NewReader("[Desktop Entry]\nType=Application\nName=App\n" + test.entry))
+		require.NoError(t, err)
+		require.Equal(t, test.expected, entry.ShouldShow(), test.entry)
+	}
+}
+
+func TestCurrentDesktops(t *testing.T) {
+	t.Setenv("XDG_CURRENT_DESKTOP", "")
+	require.Empty(t, desktop.CurrentDesktops())
+
+	t.Setenv("XDG_CURRENT_DESKTOP", "ubuntu::GNOME")
+	require.Equal(t, []string{"ubuntu", "GNOME"}, desktop.CurrentDesktops())
+}
+
+func TestCommand(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+
+	tests := []struct {
+		exec     string
+		files    []string
+		expected [][]string
+	}{
+		{
+			exec:     "editor",
+			files:    []string{"/tmp/a.txt"},
+			expected: [][]string{{"editor"}},
+		},
+		{
+			exec:     "editor %f",
+			expected: [][]string{{"editor"}},
+		},
+		{
+			exec:     "editor --file=%f",
+			files:    []string{"file:///tmp/a%20b.txt", "/tmp/c.txt"},
+			expected: [][]string{{"editor", "--file=/tmp/a b.txt"}, {"editor", "--file=/tmp/c.txt"}},
+		},
+		{
+			exec:     "editor %F",
+			files:    []string{"file:///tmp/a.txt", "https://example.com/b.txt"},
+			expected: [][]string{{"editor", "/tmp/a.txt", "https://example.com/b.txt"}},
+		},
+		{
+			exec:     "browser %u",
+			files:    []string{"https://example.com", "file:///tmp/a.html"},
+			expected: [][]string{{"browser", "https://example.com"}, {"browser", "file:///tmp/a.html"}},
+		},
+		{
+			exec:     "browser --new-tab %U",
+			files:    []string{"https://example.com", "file:///tmp/a.html"},
+			expected: [][]string{{"browser", "--new-tab", "https://example.com", "file:///tmp/a.html"}},
+		},
+		{
+			exec:     `"/opt/My App/app" %i --title=%c --entry "%k" %d %m 100%%`,
+			expected: [][]string{{"/opt/My App/app", "--icon", "app-icon", "--title=My App", "--entry", "", "100%"}},
+		},
+		{
+			exec:     "sh -c \"echo \\\"\\$HOME\\\" \\\\ \\` done\" arg\\ with\\ spaces",
+			expected: [][]string{{"sh", "-c", "echo \"$HOME\" \\ ` done", "arg with spaces"}},
+		},
+	}
+
+	for _, test := range tests {
+		entry, err := desktop.Parse(strings.NewReader("[Desktop Entry]\nName=My App\nIcon=app-icon\n"))
+		require.NoError(t, err)
+		entry.Exec = test.exec
+
+		cmds, err := entry.Command(test.files...)
+		require.NoError(t, err, test.exec)
+		require.Equal(t, test.expected, cmds, test.exec)
+	}
+
+	// Test invalid Exec values.
+	for _, exec := range []string{"", " ", `app "unterminated`, "app %x", "app --files=%F", "app %", "app --icon=%i"} {
+		entry, err := desktop.Parse(strings.NewReader("[Desktop Entry]\nName=App\n"))
+		require.NoError(t, err)
+		entry.Exec = exec
+
+		_, err = entry.Command("/tmp/a.txt")
+		require.Error(t, err, exec)
+	}
+}
+
+func TestActionCommand(t *testing.T) {
+	entry, err := desktop.Parse(strings.NewReader(editorEntry))
+	require.NoError(t, err)
+
+	cmds, err := entry.ActionCommand("new-window", "/tmp/a.txt")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"editor", "--new-window"}}, cmds)
+
+	_, err = entry.ActionCommand("missing")
+	require.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	type finding struct {
+		line     int
+		severity desktop.Severity
+	}
+
+	findings, err := desktop.Validate(strings.NewReader(editorEntry))
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	require.Equal(t, desktop.SeverityError, findings[0].Severity)
+	require.Equal(t, 20, findings[0].Line)
+	require.Equal(t, "Actions", findings[0].Key)
+	require.Equal(t, "line 20: error: missing group [Desktop Action missing] for action `missing`", findings[0].Error())
+
+	const header = "[Desktop Entry]\nType=Application\nName=App\n"
+	tests := []struct {
+		content  string
+		expected []finding
+	}{
+		{header + "Exec=app %U\nCategories=Utility;\n", nil},
+		{header + "Exec=app\n", nil},
+		{"# Comment\n[Desktop Entry]\nName=App\nName=Other\n", []finding{{2, desktop.SeverityError}, {4, desktop.SeverityError}}},
+		{"[Desktop Entry]\nType=Application\nExec=app\nCategories=Utility;\n", []finding{{1, desktop.SeverityError}}},
+		{"[Desktop Entry]\nType=Application\nName=App\nCategories=Utility;\n", []finding{{1, desktop.SeverityError}}},
+		{"[Desktop Entry]\nType=Application\nName=\nExec=app\nCategories=Utility;\n", []finding{{3, desktop.SeverityError}}},
+		{"[Desktop Entry]\nType=Application\nName=App\nDBusActivatable=true\nCategories=Utility;\n", nil},
+		{"[Desktop Entry]\nType=Link\nName=Site\n", []finding{{1, desktop.SeverityError}}},
+		{"[Desktop Entry]\nType=Link\nName=Site\nURL=https://example.com\nTerminal=true\n", []finding{{5, desktop.SeverityWarning}}},
+		{"[Desktop Entry]\nType=Directory\nName=Dir\nURL=https://example.com\n", []finding{{4, desktop.SeverityWarning}}},
+		{"[Desktop Entry]\nType=Unknown\nName=App\n", []finding{{2, desktop.SeverityError}}},
+		{"[Desktop Entry]\nType=FSDevice\nName=App\n", []finding{{2, desktop.SeverityWarning}}},
+		{"[Other]\nName=App\n[Desktop Entry]\nType=Directory\nName=Dir\n", []finding{{0, desktop.SeverityError}, {1, desktop.SeverityError}}},
+		{"[Desktop Entry]\nType=Directory\nName=Dir\n[X-Extension]\nKey=value\n[Desktop Entry]\n", []finding{{6, desktop.SeverityError}}},
+
+		// Keys and values.
+		{header + "Exec=app\nCategories=Utility;\nUnknown=value\nX-Custom=value\nInvalid_Key=value\n", []finding{{6, desktop.SeverityError}, {8, desktop.SeverityError}}},
+		{header + "Exec=app\nCategories=Utility;\nEncoding=UTF-8\nTerminal=1\nNoDisplay=yes\n", []finding{{6, desktop.SeverityWarning}, {7, desktop.SeverityWarning}, {8, desktop.SeverityError}}},
+		{header + "Exec=app\nCategories=Utility;\nName[fr]=Appli\nName[sr@latin]=Apl\nName[de_DE.UTF-8]=App\nName[FR]=App\nExec[fr]=app\nName[es_419]=App\n", []finding{{8, desktop.SeverityWarning}, {9, desktop.SeverityError}, {10, desktop.SeverityError}}},
+		{header + "Exec=app\nCategories=Utility;\nComment=Bad \\x escape\nStartupWMClass=Appé\n", []finding{{6, desktop.SeverityError}, {7, desktop.SeverityError}}},
+		{header + "Exec=app\nCategories=Utility;\nComment=App\nGenericName=Generic\n", []finding{{6, desktop.SeverityWarning}}},
+		{header + "Exec=app\nCategories=Utility;\nIcon=app.png\nVersion=0.9\n", []finding{{6, desktop.SeverityWarning}, {7, desktop.SeverityHint}}},
+		{header + "Exec=app\nCategories=Utility;\nIcon=/usr/share/pixmaps/app.png\nVersion=1.0\n", nil},
+		{header + "Exec=app\nCategories=Utility;\nOnlyShowIn=GNOME;\nNotShowIn=KDE;Unknown;X-Custom;\n", []finding{{7, desktop.SeverityError}, {7, desktop.SeverityWarning}}},
+		{header + "Exec=app\nCategories=Utility;\nMimeType=text/plain;invalid;\n", []finding{{6, desktop.SeverityError}}},
+
+		// Exec values.
+		{header + "Exec=app \"arg with spaces\" \"\\\\$HOME\" %f\nCategories=Utility;\n", nil},
+		{header + "Exec=app arg|other\nCategories=Utility;\n", []finding{{4, desktop.SeverityError}}},
+		{header + "Exec=app \"unterminated\nCategories=Utility;\n", []finding{{4, desktop.SeverityError}}},
+		{header + "Exec=app \"$HOME\"\nCategories=Utility;\n", []finding{{4, desktop.SeverityError}}},
+		{header + "Exec=app \"%f\"\nCategories=Utility;\n", []finding{{4, desktop.SeverityWarning}}},
+		{header + "Exec=app %f %U\nCategories=Utility;\n", []finding{{4, desktop.SeverityError}}},
+		{header + "Exec=app %x\nCategories=Utility;\n", []finding{{4, desktop.SeverityError}}},
+		{header + "Exec=app %d\nCategories=Utility;\n", []finding{{4, desktop.SeverityWarning}}},
+		{header + "Exec=app --file=%F\nCategories=Utility;\n", []finding{{4, desktop.SeverityError}}},
+		{header + "Exec=\nCategories=Utility;\n", []finding{{4, desktop.SeverityError}}},
+
+		// Categories.
+		{header + "Exec=app\nCategories=Utility;TextEditor;X-Custom;Utility;Unknown;TrayIcon;\n", []finding{{5, desktop.SeverityWarning}, {5, desktop.SeverityError}, {5, desktop.SeverityError}}},
+		{header + "Exec=app\nCategories=TextEditor;TrayIcon;\nOnlyShowIn=KDE;\n", []finding{{5, desktop.SeverityHint}}},
+
+		// Actions.
+		{header + "Exec=app\nCategories=Utility;\nActions=new;\n[Desktop Action new]\nName=New\nExec=app --new\n", nil},
+		{header + "Exec=app\nCategories=Utility;\nActions=new;\n[Desktop Action new]\nIcon=new\nUnknown=value\n", []finding{{7, desktop.SeverityError}, {7, desktop.SeverityError}, {9, desktop.SeverityError}}},
+		{header + "Exec=app\nCategories=Utility;\nActions=new;\n[Desktop Action new]\nName=\nExec=app --new\n", []finding{{8, desktop.SeverityError}}},
+		{header + "DBusActivatable=true\nCategories=Utility;\nActions=new;\n[Desktop Action new]\nName=New\n[Desktop Action other]\nName=Other\nExec=other\n", []finding{{9, desktop.SeverityError}}},
+	}
+	for _, test := range tests {
+		findings, err := desktop.Validate(strings.NewReader(test.content))
+		require.NoError(t, err)
+
+		actual := make([]finding, 0, len(findings))
+		for _, f := range findings {
+			actual = append(actual, finding{f.Line, f.Severity})
+		}
+		require.ElementsMatch(t, test.expected, actual, test.content)
+	}
+
+	// Test syntax errors.
+	findings, err = desktop.Validate(strings.NewReader("[Desktop Entry]\ninvalid line\nType=Application\n[Invalid\n"))
+	require.NoError(t, err)
+	require.Equal(t, []desktop.Finding{
+		{Severity: desktop.SeverityError, Line: 2, Msg: "missing `=` separator"},
+		{Severity: desktop.SeverityError, Line: 4, Msg: "malformed group header"},
+	}, findings)
+
+	// Test files.
+	name := filepath.Join(t.TempDir(), "app.desktop")
+	require.NoError(t, os.WriteFile(name, []byte(header+"Exec=app\nCategories=Utility;\n"), 0o600))
+
+	findings, err = desktop.ValidateFile(name)
+	require.NoError(t, err)
+	require.Empty(t, findings)
+
+	_, err = desktop.ValidateFile(filepath.Join(t.TempDir(), "missing.desktop"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// Test entries.
+	entry := &desktop.Entry{Type: desktop.TypeApplication, Name: "App", Exec: "app %u", Categories: []string{"Unknown"}}
+	findings = entry.Validate()
+	require.Len(t, findings, 2)
+	require.Equal(t, desktop.Finding{
+		Severity: desktop.SeverityError,
+		Group:    "Desktop Entry",
+		Key:      "Categories",
+		Msg:      "unknown category `Unknown`",
+	}, findings[0])
+	require.Equal(t, "error: unknown category `Unknown`", findings[0].Error())
+	require.Equal(t, desktop.SeverityHint, findings[1].Severity)
+	require.Equal(t, "hint", findings[1].Severity.String())
+}
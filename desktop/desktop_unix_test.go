@@ -0,0 +1,540 @@
+//go:build aix || dragonfly || freebsd || (js && wasm) || nacl || linux || netbsd || openbsd || solaris
+
+package desktop_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adrg/xdg"
+	"github.com/adrg/xdg/desktop"
+)
+
+// writeEntry writes the specified desktop entry content to the specified
+// path, creating the parent directories if needed.
+func writeEntry(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModeDir|0o700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+// setApplicationDirs points xdg.ApplicationDirs and the config directories
+// to temporary directories and unsets the current desktop. It returns the
+// applications directory of the data home and the applications directory
+// of the first data dir.
+func setApplicationDirs(t *testing.T) (string, string) {
+	t.Cleanup(xdg.Reload)
+
+	home := t.TempDir()
+	dataHome, dataDir := filepath.Join(home, "data"), filepath.Join(home, "system")
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("XDG_DATA_DIRS", dataDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(home, "etc"))
+	t.Setenv("XDG_CURRENT_DESKTOP", "")
+	xdg.Reload()
+
+	// Exclude the system application directories, which are always part of
+	// xdg.ApplicationDirs, so that installed applications are not used.
+	userDir, systemDir := filepath.Join(dataHome, "applications"), filepath.Join(dataDir, "applications")
+	xdg.ApplicationDirs = []string{userDir, systemDir}
+
+	return userDir, systemDir
+}
+
+func TestLookup(t *testing.T) {
+	userDir, systemDir := setApplicationDirs(t)
+
+	// Test entries in the root of application directories.
+	writeEntry(t, filepath.Join(systemDir, "xdg-test-editor.desktop"), editorEntry)
+
+	entry, err := desktop.Lookup("xdg-test-editor.desktop")
+	require.NoError(t, err)
+	require.Equal(t, "xdg-test-editor.desktop", entry.ID)
+	require.Equal(t, filepath.Join(systemDir, "xdg-test-editor.desktop"), entry.Path)
+
+	// Test IDs without the .desktop extension.
+	entry, err = desktop.Lookup("xdg-test-editor")
+	require.NoError(t, err)
+	require.Equal(t, "xdg-test-editor.desktop", entry.ID)
+
+	// Test entries in subdirectories.
+	path := filepath.Join(systemDir, "xdg-test", "nested", "viewer.desktop")
+	writeEntry(t, path, "[Desktop Entry]\nType=Application\nName=Viewer\n")
+
+	entry, err = desktop.Lookup("xdg-test-nested-viewer.desktop")
+	require.NoError(t, err)
+	require.Equal(t, "xdg-test-nested-viewer.desktop", entry.ID)
+	require.Equal(t, path, entry.Path)
+
+	// Test precedence of application directories.
+	path = filepath.Join(userDir, "xdg-test-editor.desktop")
+	writeEntry(t, path, "[Desktop Entry]\nType=Application\nName=User Editor\n")
+
+	entry, err = desktop.Lookup("xdg-test-editor.desktop")
+	require.NoError(t, err)
+	require.Equal(t, "User Editor", entry.Name)
+	require.Equal(t, path, entry.Path)
+
+	// Test hidden entries.
+	writeEntry(t, path, "[Desktop Entry]\nType=Application\nName=User Editor\nHidden=true\n")
+
+	_, err = desktop.Lookup("xdg-test-editor.desktop")
+	require.ErrorIs(t, err, desktop.ErrNotFound)
+
+	// Test missing and invalid IDs.
+	_, err = desktop.Lookup("xdg-test-missing.desktop")
+	require.ErrorIs(t, err, desktop.ErrNotFound)
+
+	_, err = desktop.Lookup("xdg-test/editor.desktop")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, desktop.ErrNotFound)
+}
+
+func TestAll(t *testing.T) {
+	userDir, systemDir := setApplicationDirs(t)
+
+	writeEntry(t, filepath.Join(userDir, "xdg-test-editor.desktop"), "[Desktop Entry]\nName=User Editor\n")
+	writeEntry(t, filepath.Join(userDir, "xdg-test-hidden.desktop"), "[Desktop Entry]\nName=Hidden\nHidden=true\n")
+	writeEntry(t, filepath.Join(userDir, "xdg-test-invalid.desktop"), "[Other Group]\nName=Invalid\n")
+	writeEntry(t, filepath.Join(userDir, "xdg-test-notes.txt"), "[Desktop Entry]\nName=Notes\n")
+	writeEntry(t, filepath.Join(systemDir, "xdg-test-editor.desktop"), "[Desktop Entry]\nName=System Editor\n")
+	writeEntry(t, filepath.Join(systemDir, "xdg-test-hidden.desktop"), "[Desktop Entry]\nName=Masked\n")
+	writeEntry(t, filepath.Join(systemDir, "xdg-test-invalid.desktop"), "[Desktop Entry]\nName=Shadowed\n")
+	writeEntry(t, filepath.Join(systemDir, "xdg-test", "viewer.desktop"), "[Desktop Entry]\nName=Viewer\n")
+	require.NoError(t, os.Symlink(
+		filepath.Join(systemDir, "xdg-test", "viewer.desktop"),
+		filepath.Join(systemDir, "xdg-test-link.desktop"),
+	))
+
+	var (
+		names []string
+		ids   []string
+		errs  int
+	)
+	for entry, err := range desktop.All() {
+		if err != nil {
+			errs++
+			continue
+		}
+		if strings.HasPrefix(entry.ID, "xdg-test") {
+			ids = append(ids, entry.ID)
+			names = append(names, entry.Name)
+		}
+	}
+
+	require.Equal(t, 1, errs)
+	require.Equal(t, []string{
+		"xdg-test-editor.desktop",
+		"xdg-test-viewer.desktop",
+		"xdg-test-link.desktop",
+	}, ids)
+	require.Equal(t, []string{"User Editor", "Viewer", "Viewer"}, names)
+
+	// Test stopping the iteration early.
+	count := 0
+	for range desktop.All() {
+		count++
+		break
+	}
+	require.Equal(t, 1, count)
+}
+
+func TestMimeAppsFiles(t *testing.T) {
+	userDir, systemDir := setApplicationDirs(t)
+	t.Setenv("XDG_CURRENT_DESKTOP", "ubuntu:GNOME")
+
+	var expected []string
+	for _, dir := range []string{xdg.ConfigHome, xdg.ConfigDirs[0], userDir, systemDir} {
+		expected = append(expected,
+			filepath.Join(dir, "ubuntu-mimeapps.list"),
+			filepath.Join(dir, "gnome-mimeapps.list"),
+			filepath.Join(dir, "mimeapps.list"),
+		)
+	}
+	require.Equal(t, expected, desktop.MimeAppsFiles())
+}
+
+func TestDefaultApplication(t *testing.T) {
+	userDir, systemDir := setApplicationDirs(t)
+
+	for _, id := range []string{"xdg-test-a", "xdg-test-b", "xdg-test-c", "xdg-test-d", "xdg-test-e"} {
+		writeEntry(t, filepath.Join(systemDir, id+".desktop"), "[Desktop Entry]\nType=Application\nName="+id+"\n")
+	}
+
+	ids := func(entries []*desktop.Entry) []string {
+		var ids []string
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+		return ids
+	}
+
+	// Test missing associations.
+	_, err := desktop.DefaultApplication("image/png")
+	require.ErrorIs(t, err, desktop.ErrNotFound)
+	_, err = desktop.Applications("image/png")
+	require.ErrorIs(t, err, desktop.ErrNotFound)
+
+	// Test mimeinfo.cache fallback.
+	writeEntry(t, filepath.Join(systemDir, "mimeinfo.cache"),
+		"[MIME Cache]\nimage/png=xdg-test-missing.desktop;xdg-test-e.desktop;xdg-test-d.desktop;\n")
+
+	entry, err := desktop.DefaultApplication("image/png")
+	require.NoError(t, err)
+	require.Equal(t, "xdg-test-e.desktop", entry.ID)
+
+	// Test added and removed associations.
+	writeEntry(t, filepath.Join(systemDir, "mimeapps.list"), `[Added Associations]
+image/png=xdg-test-c.desktop;xdg-test-b.desktop;
+
+[Removed Associations]
+image/png=xdg-test-e.desktop;
+`)
+	writeEntry(t, filepath.Join(xdg.ConfigHome, "mimeapps.list"), `[Added Associations]
+image/png=xdg-test-b.desktop;
+
+[Removed Associations]
+image/png=xdg-test-c.desktop;
+`)
+
+	entries, err := desktop.Applications("image/png")
+	require.NoError(t, err)
+	require.Equal(t, []string{"xdg-test-b.desktop", "xdg-test-d.desktop"}, ids(entries))
+
+	// Test default applications.
+	writeEntry(t, filepath.Join(userDir, "mimeapps.list"), `[Default Applications]
+image/png=xdg-test-missing.desktop;xdg-test-d.desktop;
+`)
+
+	entry, err = desktop.DefaultApplication("image/png")
+	require.NoError(t, err)
+	require.Equal(t, "xdg-test-d.desktop", entry.ID)
+
+	entries, err = desktop.Applications("image/png")
+	require.NoError(t, err)
+	require.Equal(t, []string{"xdg-test-d.desktop", "xdg-test-b.desktop"}, ids(entries))
+
+	// Test desktop specific files.
+	t.Setenv("XDG_CURRENT_DESKTOP", "XFCE")
+	writeEntry(t, filepath.Join(userDir, "xfce-mimeapps.list"), `[Default Applications]
+image/png=xdg-test-a.desktop
+`)
+
+	entry, err = desktop.DefaultApplication("image/png")
+	require.NoError(t, err)
+	require.Equal(t, "xdg-test-a.desktop", entry.ID)
+}
+
+func TestSetDefaultApplication(t *testing.T) {
+	_, systemDir := setApplicationDirs(t)
+
+	for _, id := range []string{"xdg-test-a", "xdg-test-b"} {
+		writeEntry(t, filepath.Join(systemDir, id+".desktop"), "[Desktop Entry]\nType=Application\nName="+id+"\n")
+	}
+
+	// Test creating the mimeapps.list file.
+	require.NoError(t, desktop.SetDefaultApplication("xdg-test-a.desktop", "image/png", "x-scheme-handler/https"))
+
+	name := filepath.Join(xdg.ConfigHome, "mimeapps.list")
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	require.Equal(t, `[Default Applications]
+image/png=xdg-test-a.desktop;
+x-scheme-handler/https=xdg-test-a.desktop;
+
+[Added Associations]
+image/png=xdg-test-a.desktop;
+x-scheme-handler/https=xdg-test-a.desktop;
+`, string(data))
+
+	entry, err := desktop.DefaultApplication("x-scheme-handler/https")
+	require.NoError(t, err)
+	require.Equal(t, "xdg-test-a.desktop", entry.ID)
+
+	// Test editing existing files.
+	writeEntry(t, name, `# User associations.
+[Default Applications]
+text/plain=xdg-test-b.desktop;
+image/png=xdg-test-a.desktop;
+
+[Removed Associations]
+# Unwanted viewers.
+image/png=xdg-test-b.desktop;other.desktop;
+`)
+
+	require.NoError(t, desktop.SetDefaultApplication("xdg-test-b.desktop", "image/png"))
+	require.NoError(t, desktop.AddAssociation("xdg-test-a.desktop", "image/png"))
+	require.NoError(t, desktop.AddAssociation("xdg-test-a.desktop", "image/png"))
+	require.NoError(t, desktop.RemoveAssociation("xdg-test-b.desktop", "text/plain"))
+
+	data, err = os.ReadFile(name)
+	require.NoError(t, err)
+	require.Equal(t, `# User associations.
+[Default Applications]
+image/png=xdg-test-b.desktop;
+
+[Removed Associations]
+# Unwanted viewers.
+image/png=other.desktop;
+text/plain=xdg-test-b.desktop;
+
+[Added Associations]
+image/png=xdg-test-b.desktop;xdg-test-a.desktop;
+`, string(data))
+
+	entries, err := desktop.Applications("image/png")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "xdg-test-b.desktop", entries[0].ID)
+	require.Equal(t, "xdg-test-a.desktop", entries[1].ID)
+
+	_, err = desktop.DefaultApplication("text/plain")
+	require.ErrorIs(t, err, desktop.ErrNotFound)
+
+	// Test invalid arguments.
+	require.Error(t, desktop.SetDefaultApplication("xdg-test-a", "image/png"))
+	require.Error(t, desktop.SetDefaultApplication("xdg-test-a.desktop"))
+	require.Error(t, desktop.AddAssociation("xdg-test-a.desktop", "image"))
+	require.Error(t, desktop.RemoveAssociation("xdg-test-a.desktop", "image/png;"))
+}
+
+func TestOpenCommand(t *testing.T) {
+	userDir, systemDir := setApplicationDirs(t)
+	t.Setenv("TERMINAL", "xdg-test-term")
+
+	writeEntry(t, filepath.Join(systemDir, "xdg-test-editor.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Editor\nExec=editor %F\nPath=/tmp\n")
+	writeEntry(t, filepath.Join(systemDir, "xdg-test-browser.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Browser\nExec=browser --url %u\n")
+	writeEntry(t, filepath.Join(systemDir, "xdg-test-files.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Files\nExec=files %U\nTerminal=true\n")
+	writeEntry(t, filepath.Join(userDir, "mimeapps.list"), `[Default Applications]
+text/plain=xdg-test-editor.desktop
+application/x-xdg-parent=xdg-test-editor.desktop
+x-scheme-handler/https=xdg-test-browser.desktop
+inode/directory=xdg-test-files.desktop
+`)
+
+	writeEntry(t, filepath.Join(filepath.Dir(systemDir), "mime", "globs2"), `50:text/plain:*.txt
+50:text/x-go:*.go
+50:application/x-xdg-test:*.xdg-test
+`)
+	writeEntry(t, filepath.Join(filepath.Dir(systemDir), "mime", "subclasses"),
+		"application/x-xdg-test application/x-xdg-parent\n")
+
+	dir := t.TempDir()
+	textFile := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(textFile, []byte("notes"), 0o600))
+	sniffedFile := filepath.Join(dir, "notes")
+	require.NoError(t, os.WriteFile(sniffedFile, []byte("plain text content"), 0o600))
+	goFile := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(goFile, []byte("package main"), 0o600))
+	binFile := filepath.Join(dir, "data.bin")
+	require.NoError(t, os.WriteFile(binFile, []byte{0, 1, 2, 3}, 0o600))
+	testFile := filepath.Join(dir, "data.xdg-test")
+	require.NoError(t, os.WriteFile(testFile, []byte{0, 1, 2, 3}, 0o600))
+
+	tests := []struct {
+		target string
+		args   []string
+		dir    string
+	}{
+		{target: textFile, args: []string{"editor", textFile}, dir: "/tmp"},
+		{target: "file://" + textFile, args: []string{"editor", textFile}, dir: "/tmp"},
+		{target: sniffedFile, args: []string{"editor", sniffedFile}, dir: "/tmp"},
+		{target: goFile, args: []string{"editor", goFile}, dir: "/tmp"},
+		{target: testFile, args: []string{"editor", testFile}, dir: "/tmp"},
+		{target: "https://example.com", args: []string{"browser", "--url", "https://example.com"}},
+		{target: dir, args: []string{"xdg-test-term", "-e", "files", dir}},
+	}
+
+	for _, test := range tests {
+		cmd, err := desktop.OpenCommand(test.target)
+		require.NoError(t, err, test.target)
+		require.Equal(t, test.args, cmd.Args)
+		require.Equal(t, test.dir, cmd.Dir)
+	}
+
+	// Test relative paths of existing files, which take precedence over URLs.
+	t.Chdir(dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "https:notes.txt"), []byte("notes"), 0o600))
+	for _, target := range []string{"notes.txt", "https:notes.txt"} {
+		cmd, err := desktop.OpenCommand(target)
+		require.NoError(t, err, target)
+		require.Equal(t, []string{"editor", filepath.Join(dir, target)}, cmd.Args)
+		require.Equal(t, "/tmp", cmd.Dir)
+	}
+
+	// Test targets without associated applications.
+	_, err := desktop.OpenCommand(binFile)
+	require.ErrorIs(t, err, desktop.ErrNotFound)
+
+	_, err = desktop.OpenCommand("mailto:user@example.com")
+	require.ErrorIs(t, err, desktop.ErrNotFound)
+
+	// Test missing files.
+	_, err = desktop.OpenCommand(filepath.Join(dir, "missing.txt"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestInstall(t *testing.T) {
+	userDir, systemDir := setApplicationDirs(t)
+
+	dir := desktop.InstallDir()
+	if os.Geteuid() == 0 {
+		require.Equal(t, systemDir, dir)
+	} else {
+		require.Equal(t, userDir, dir)
+	}
+	writeEntry(t, filepath.Join(dir, "mimeinfo.cache"), "[MIME Cache]\nimage/png=xdg-test-app.desktop;stale.desktop;\n")
+	writeEntry(t, filepath.Join(dir, "other.desktop"), "[Desktop Entry]\nType=Application\nName=Other\nExec=other\nMimeType=text/plain;\n")
+
+	entry := &desktop.Entry{
+		ID:         "xdg-test-app",
+		Type:       desktop.TypeApplication,
+		Name:       "Test App",
+		Comment:    " Leading space",
+		Exec:       `xdg-test-app "%f"`,
+		Terminal:   true,
+		MimeType:   []string{"text/plain", "x-scheme-handler/xdg-test"},
+		Categories: []string{"Utility", "X-Semi;colon"},
+		Actions: []*desktop.Action{
+			{ID: "new", Name: "New Window", Exec: "xdg-test-app --new"},
+		},
+	}
+	require.NoError(t, desktop.Install(entry))
+	require.Equal(t, "xdg-test-app.desktop", entry.ID)
+	require.Equal(t, filepath.Join(dir, "xdg-test-app.desktop"), entry.Path)
+
+	data, err := os.ReadFile(entry.Path)
+	require.NoError(t, err)
+	require.Equal(t, `[Desktop Entry]
+Type=Application
+Name=Test App
+Comment=\sLeading space
+Exec=xdg-test-app "%f"
+Terminal=true
+Actions=new;
+MimeType=text/plain;x-scheme-handler/xdg-test;
+Categories=Utility;X-Semi\;colon;
+
+[Desktop Action new]
+Name=New Window
+Exec=xdg-test-app --new
+`, string(data))
+
+	data, err = os.ReadFile(filepath.Join(dir, "mimeinfo.cache"))
+	require.NoError(t, err)
+	require.Equal(t, `[MIME Cache]
+text/plain=other.desktop;xdg-test-app.desktop;
+x-scheme-handler/xdg-test=xdg-test-app.desktop;
+`, string(data))
+
+	installed, err := desktop.Lookup("xdg-test-app")
+	require.NoError(t, err)
+	require.Equal(t, entry.Comment, installed.Comment)
+	require.Equal(t, entry.Categories, installed.Categories)
+	require.Len(t, installed.Actions, 1)
+
+	apps, err := desktop.Applications("x-scheme-handler/xdg-test")
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	require.Equal(t, "xdg-test-app.desktop", apps[0].ID)
+
+	// Reinstall parsed entry, preserving unchanged localized values and
+	// extension keys. The localized values of changed fields are dropped.
+	t.Setenv("LC_ALL", "fr_FR.UTF-8")
+	writeEntry(t, installed.Path, "[Desktop Entry]\nType=Application\nName=Test App\nName[fr]=Appli\n"+
+		"Comment=Test comment\nComment[fr]=Commentaire\nExec=xdg-test-app\nX-Test=value\n")
+	installed, err = desktop.Lookup("xdg-test-app")
+	require.NoError(t, err)
+	require.Equal(t, "Commentaire", installed.Comment)
+	installed.Name, installed.MimeType = "Renamed App", []string{"image/png"}
+	require.NoError(t, desktop.Install(installed))
+
+	data, err = os.ReadFile(installed.Path)
+	require.NoError(t, err)
+	require.Equal(t, "[Desktop Entry]\nType=Application\nName=Renamed App\nComment=Test comment\nExec=xdg-test-app\n"+
+		"MimeType=image/png;\nComment[fr]=Commentaire\nX-Test=value\n", string(data))
+
+	value, ok := installed.Value("X-Test")
+	require.True(t, ok)
+	require.Equal(t, "value", value)
+
+	// Uninstall entry.
+	require.NoError(t, desktop.Uninstall("xdg-test-app.desktop"))
+	require.NoFileExists(t, installed.Path)
+
+	data, err = os.ReadFile(filepath.Join(dir, "mimeinfo.cache"))
+	require.NoError(t, err)
+	require.Equal(t, "[MIME Cache]\ntext/plain=other.desktop;\n", string(data))
+
+	_, err = desktop.Lookup("xdg-test-app")
+	require.ErrorIs(t, err, desktop.ErrNotFound)
+	require.ErrorIs(t, desktop.Uninstall("xdg-test-app"), desktop.ErrNotFound)
+
+	// Invalid entries.
+	invalid := []*desktop.Entry{
+		{ID: "", Type: desktop.TypeApplication, Name: "App", Exec: "app"},
+		{ID: "a/b", Type: desktop.TypeApplication, Name: "App", Exec: "app"},
+		{ID: "app", Name: "App", Exec: "app"},
+		{ID: "app", Type: desktop.TypeApplication, Exec: "app"},
+		{ID: "app", Type: desktop.TypeApplication, Name: "App"},
+		{ID: "app", Type: desktop.TypeLink, Name: "App"},
+		{ID: "app", Type: desktop.TypeApplication, Name: "App", Exec: "app", MimeType: []string{"text"}},
+		{ID: "app", Type: desktop.TypeApplication, Name: "App", Exec: "app", Actions: []*desktop.Action{{ID: "a"}}},
+		{ID: "app", Type: desktop.TypeApplication, Name: "App", Exec: "app", Actions: []*desktop.Action{{ID: "new", Exec: "app --new"}}},
+	}
+	for _, entry := range invalid {
+		require.Error(t, desktop.Install(entry), entry.ID)
+	}
+	require.NoFileExists(t, filepath.Join(dir, "app.desktop"))
+}
+
+func TestUpdateMimeCache(t *testing.T) {
+	userDir, _ := setApplicationDirs(t)
+
+	writeEntry(t, filepath.Join(userDir, "xdg-test-a.desktop"),
+		"[Desktop Entry]\nType=Application\nName=A\nExec=a\nMimeType=application/x-xdg-test;text/x-xdg-test;invalid;\n")
+	writeEntry(t, filepath.Join(userDir, "xdg-test", "b.desktop"),
+		"[Desktop Entry]\nType=Application\nName=B\nExec=b\nMimeType=application/x-xdg-test;\n")
+	writeEntry(t, filepath.Join(userDir, "xdg-test-hidden.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Hidden\nHidden=true\nMimeType=application/x-xdg-test;\n")
+	writeEntry(t, filepath.Join(userDir, "xdg-test-invalid.desktop"), "MimeType=application/x-xdg-test;\n")
+
+	ids := func(mimeType string) []string {
+		entries, err := desktop.Applications(mimeType)
+		require.NoError(t, err)
+
+		var ids []string
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+		return ids
+	}
+
+	// Test directories without a mimeinfo.cache file.
+	_, err := desktop.Applications("application/x-xdg-test")
+	require.ErrorIs(t, err, desktop.ErrNotFound)
+
+	// Test generating the mimeinfo.cache file.
+	require.NoError(t, desktop.UpdateMimeCache(userDir))
+
+	data, err := os.ReadFile(filepath.Join(userDir, "mimeinfo.cache"))
+	require.NoError(t, err)
+	require.Equal(t, `[MIME Cache]
+application/x-xdg-test=xdg-test-a.desktop;xdg-test-b.desktop;
+text/x-xdg-test=xdg-test-a.desktop;
+`, string(data))
+
+	// Test reading the mimeinfo.cache file.
+	writeEntry(t, filepath.Join(userDir, "mimeinfo.cache"), "[MIME Cache]\napplication/x-xdg-test=xdg-test-b.desktop;\n")
+	require.Equal(t, []string{"xdg-test-b.desktop"}, ids("application/x-xdg-test"))
+
+	require.Error(t, desktop.UpdateMimeCache(filepath.Join(userDir, "missing")))
+}
@@ -0,0 +1,96 @@
+/*
+Package desktop provides an implementation of the Desktop Entry Specification.
+Desktop entries describe how applications are launched, how they appear in
+menus and which file types they handle. The package parses desktop entry files
+and locates applications in the directories defined by xdg.ApplicationDirs.
+
+	For more information regarding the Desktop Entry Specification see:
+	https://specifications.freedesktop.org/desktop-entry-spec/latest/
+
+# Usage
+
+	package main
+
+	import (
+		"fmt"
+		"log"
+		"strings"
+
+		"github.com/adrg/xdg/desktop"
+	)
+
+	func main() {
+		// Look up an application by its desktop file ID.
+		entry, err := desktop.Lookup("org.gnome.Maps.desktop")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println("Name:", entry.Name)
+		fmt.Println("Exec:", entry.Exec)
+		fmt.Println("Path:", entry.Path)
+
+		// Build the command lines used to open files with the application.
+		cmds, err := entry.Command("/tmp/a.png", "/tmp/b.png")
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, cmd := range cmds {
+			fmt.Println(strings.Join(cmd, " "))
+		}
+
+		// Find the default application used to open PNG images.
+		if entry, err := desktop.DefaultApplication("image/png"); err == nil {
+			fmt.Println("Image viewer:", entry.Name)
+		}
+
+		// Set the default application used to open HTTPS URLs.
+		if err := desktop.SetDefaultApplication("org.mozilla.firefox.desktop", "x-scheme-handler/https"); err != nil {
+			log.Fatal(err)
+		}
+
+		// Open a URL with the default web browser.
+		cmd, err := desktop.OpenCommand("https://example.com")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := cmd.Start(); err != nil {
+			log.Fatal(err)
+		}
+
+		// List the applications which should be displayed in menus.
+		for entry, err := range desktop.All() {
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			if entry.Type == desktop.TypeApplication && entry.ShouldShow() {
+				fmt.Println(entry.ID, entry.Name)
+			}
+		}
+
+		// Validate a desktop entry file.
+		findings, err := desktop.ValidateFile("org.example.App.desktop")
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, finding := range findings {
+			fmt.Println(finding)
+		}
+
+		// Install a desktop entry for an application.
+		err = desktop.Install(&desktop.Entry{
+			ID:         "org.example.App",
+			Type:       desktop.TypeApplication,
+			Name:       "Example App",
+			Icon:       "org.example.App",
+			Exec:       "example-app %F",
+			MimeType:   []string{"text/plain"},
+			Categories: []string{"Utility"},
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+*/
+package desktop
@@ -0,0 +1,259 @@
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/adrg/xdg/internal/keyfile"
+	"github.com/adrg/xdg/internal/locale"
+)
+
+// Desktop entry types.
+const (
+	TypeApplication = "Application"
+	TypeLink        = "Link"
+	TypeDirectory   = "Directory"
+)
+
+const (
+	entryGroup        = "Desktop Entry"
+	actionGroupPrefix = "Desktop Action "
+)
+
+// Action represents an additional application action, defined in a
+// [Desktop Action <id>] group of a desktop entry.
+type Action struct {
+	// ID contains the identifier of the action.
+	ID string
+
+	// Name contains the localized label of the action.
+	Name string
+
+	// Icon contains the localized icon of the action.
+	Icon string
+
+	// Exec contains the command line used to run the action.
+	Exec string
+}
+
+// Entry represents a desktop entry, as defined by the Desktop Entry
+// Specification. Localized values are resolved using the locale defined by
+// the LC_ALL, LC_MESSAGES and LANG environment variables.
+type Entry struct {
+	// ID contains the desktop file ID of the entry (e.g. org.gnome.Maps.desktop).
+	// It is only set for entries located inside an application directory.
+	ID string
+
+	// Path contains the location of the desktop entry file, if any.
+	Path string
+
+	// Type contains the entry type (Type key): Application, Link or Directory.
+	Type string
+
+	// Version contains the specification version of the entry (Version key).
+	Version string
+
+	// Name contains the localized name of the application (Name key).
+	Name string
+
+	// GenericName contains the localized generic name (GenericName key).
+	GenericName string
+
+	// NoDisplay specifies whether the entry is hidden from menus (NoDisplay key).
+	NoDisplay bool
+
+	// Comment contains the localized tooltip of the entry (Comment key).
+	Comment string
+
+	// Icon contains the localized icon name or path (Icon key).
+	Icon string
+
+	// Hidden specifies whether the entry is considered deleted (Hidden key).
+	Hidden bool
+
+	// OnlyShowIn contains the desktops which show the entry (OnlyShowIn key).
+	OnlyShowIn []string
+
+	// NotShowIn contains the desktops which hide the entry (NotShowIn key).
+	NotShowIn []string
+
+	// DBusActivatable specifies whether to launch via D-Bus (DBusActivatable key).
+	DBusActivatable bool
+
+	// TryExec contains the executable checked for existence (TryExec key).
+	TryExec string
+
+	// Exec contains the command line used to launch the application (Exec key).
+	Exec string
+
+	// WorkingDir contains the working directory of the application (Path key).
+	WorkingDir string
+
+	// Terminal specifies whether to run in a terminal window (Terminal key).
+	Terminal bool
+
+	// Actions contains the additional application actions (Actions key).
+	Actions []*Action
+
+	// MimeType contains the supported MIME types (MimeType key).
+	MimeType []string
+
+	// Categories contains the menu categories of the entry (Categories key).
+	Categories []string
+
+	// Implements contains the implemented interfaces (Implements key).
+	Implements []string
+
+	// Keywords contains the localized search keywords (Keywords key).
+	Keywords []string
+
+	// StartupNotify specifies startup notification support (StartupNotify key).
+	StartupNotify bool
+
+	// StartupWMClass contains the application window class (StartupWMClass key).
+	StartupWMClass string
+
+	// URL contains the location accessed by a Link entry (URL key).
+	URL string
+
+	// PrefersNonDefaultGPU prefers a discrete GPU (PrefersNonDefaultGPU key).
+	PrefersNonDefaultGPU bool
+
+	// SingleMainWindow specifies a single main window app (SingleMainWindow key).
+	SingleMainWindow bool
+
+	group  *keyfile.Group
+	locale locale.Locale
+}
+
+// ParseFile parses the desktop entry file at the specified location.
+func ParseFile(name string) (*Entry, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	entry, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	entry.Path = name
+
+	return entry, nil
+}
+
+// Parse parses the desktop entry contained in the provided reader.
+// The content must include a [Desktop Entry] group.
+func Parse(r io.Reader) (*Entry, error) {
+	kf, err := keyfile.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return newEntry(kf, locale.Messages())
+}
+
+func newEntry(kf *keyfile.File, loc locale.Locale) (*Entry, error) {
+	group := kf.Group(entryGroup)
+	if group == nil {
+		return nil, errors.New("missing [Desktop Entry] group")
+	}
+
+	e := &Entry{group: group, locale: loc}
+	e.Type, _ = e.Value("Type")
+	e.Version, _ = e.Value("Version")
+	e.Name, _ = e.LocalizedValue("Name")
+	e.GenericName, _ = e.LocalizedValue("GenericName")
+	e.NoDisplay = e.boolValue("NoDisplay")
+	e.Comment, _ = e.LocalizedValue("Comment")
+	e.Icon, _ = e.LocalizedValue("Icon")
+	e.Hidden = e.boolValue("Hidden")
+	e.OnlyShowIn = e.listValue("OnlyShowIn")
+	e.NotShowIn = e.listValue("NotShowIn")
+	e.DBusActivatable = e.boolValue("DBusActivatable")
+	e.TryExec, _ = e.Value("TryExec")
+	e.Exec, _ = e.Value("Exec")
+	e.WorkingDir, _ = e.Value("Path")
+	e.Terminal = e.boolValue("Terminal")
+	e.MimeType = e.listValue("MimeType")
+	e.Categories = e.listValue("Categories")
+	e.Implements = e.listValue("Implements")
+	e.StartupNotify = e.boolValue("StartupNotify")
+	e.StartupWMClass, _ = e.Value("StartupWMClass")
+	e.URL, _ = e.Value("URL")
+	e.PrefersNonDefaultGPU = e.boolValue("PrefersNonDefaultGPU")
+	e.SingleMainWindow = e.boolValue("SingleMainWindow")
+	if value, ok := group.LocalizedValue("Keywords", loc); ok {
+		e.Keywords = keyfile.List(value)
+	}
+
+	// Parse actions. Actions without a corresponding group are ignored.
+	for _, id := range e.listValue("Actions") {
+		group := kf.Group(actionGroupPrefix + id)
+		if group == nil {
+			continue
+		}
+
+		action := &Action{ID: id}
+		if value, ok := group.LocalizedValue("Name", loc); ok {
+			action.Name = keyfile.String(value)
+		}
+		if value, ok := group.LocalizedValue("Icon", loc); ok {
+			action.Icon = keyfile.String(value)
+		}
+		if value, ok := group.Value("Exec"); ok {
+			action.Exec = keyfile.String(value)
+		}
+		e.Actions = append(e.Actions, action)
+	}
+
+	return e, nil
+}
+
+// Value returns the unescaped value of the specified key of the
+// [Desktop Entry] group. It can be used to retrieve the values of keys which
+// are not exposed as fields of the entry, such as extension keys (X-*).
+func (e *Entry) Value(key string) (string, bool) {
+	if e.group == nil {
+		return "", false
+	}
+
+	value, ok := e.group.Value(key)
+	return keyfile.String(value), ok
+}
+
+// LocalizedValue returns the unescaped value of the specified localized key
+// of the [Desktop Entry] group, matching the locale of the entry. If no
+// localized value matches the locale, the non-localized value is returned.
+func (e *Entry) LocalizedValue(key string) (string, bool) {
+	if e.group == nil {
+		return "", false
+	}
+
+	value, ok := e.group.LocalizedValue(key, e.locale)
+	return keyfile.String(value), ok
+}
+
+func (e *Entry) boolValue(key string) bool {
+	value, ok := e.group.Value(key)
+	if !ok {
+		return false
+	}
+
+	b, _ := keyfile.Bool(value)
+	return b
+}
+
+func (e *Entry) listValue(key string) []string {
+	value, ok := e.group.Value(key)
+	if !ok {
+		return nil
+	}
+
+	return keyfile.List(value)
+}
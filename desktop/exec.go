@@ -0,0 +1,256 @@
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// execArg represents an argument of an Exec value, before the expansion of
+// field codes.
+type execArg struct {
+	text   string
+	quoted bool
+}
+
+// Command returns the command lines used to launch the entry with the
+// specified files or URLs, obtained by expanding the field codes of the
+// Exec value of the entry. Each returned command line contains the program
+// to run, followed by its arguments, and can be used to build an exec.Cmd.
+//
+// If the Exec value contains the %f or %u field codes and more than one
+// file or URL is provided, a command line is returned for each of them.
+// Otherwise, a single command line is returned. The %F and %U field codes
+// are expanded to all the specified files or URLs. For the %f and %F field
+// codes, file:// URLs are converted to local paths. Files and URLs are
+// ignored if the Exec value does not contain any of these field codes.
+//
+// The %i, %c and %k field codes are expanded to the --icon option followed
+// by the icon of the entry, the name of the entry and the location of the
+// desktop entry file, respectively. The deprecated field codes are removed.
+//
+// The Terminal key of the entry is not taken into account, so the caller
+// is responsible for running the command in a terminal, if required.
+func (e *Entry) Command(files ...string) ([][]string, error) {
+	if e.Exec == "" {
+		return nil, errors.New("missing Exec key")
+	}
+
+	return e.expandExec(e.Exec, files)
+}
+
+// ActionCommand returns the command lines used to launch the action with
+// the specified ID, with the specified files or URLs. See Entry.Command
+// for more details.
+func (e *Entry) ActionCommand(id string, files ...string) ([][]string, error) {
+	for _, action := range e.Actions {
+		if action.ID != id {
+			continue
+		}
+		if action.Exec == "" {
+			return nil, fmt.Errorf("missing Exec key for action `%s`", id)
+		}
+
+		return e.expandExec(action.Exec, files)
+	}
+
+	return nil, fmt.Errorf("action `%s` not found", id)
+}
+
+func (e *Entry) expandExec(value string, files []string) ([][]string, error) {
+	args, err := splitExec(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Exec value `%s`: %w", value, err)
+	}
+
+	// Launch a separate instance for each file, if the command accepts
+	// a single file.
+	if len(files) > 1 && acceptsSingleFile(args) {
+		cmds := make([][]string, 0, len(files))
+		for _, file := range files {
+			cmd, err := e.expandArgs(args, []string{file})
+			if err != nil {
+				return nil, err
+			}
+			cmds = append(cmds, cmd)
+		}
+
+		return cmds, nil
+	}
+
+	cmd, err := e.expandArgs(args, files)
+	if err != nil {
+		return nil, err
+	}
+
+	return [][]string{cmd}, nil
+}
+
+func (e *Entry) expandArgs(args []execArg, files []string) ([]string, error) {
+	var cmd []string
+	for _, arg := range args {
+		if !arg.quoted {
+			switch arg.text {
+			case "%F":
+				for _, file := range files {
+					cmd = append(cmd, localPath(file))
+				}
+				continue
+			case "%U":
+				cmd = append(cmd, files...)
+				continue
+			case "%i":
+				if e.Icon != "" {
+					cmd = append(cmd, "--icon", e.Icon)
+				}
+				continue
+			case "%d", "%D", "%n", "%N", "%v", "%m":
+				// Remove deprecated field codes.
+				continue
+			case "%f", "%u":
+				// Remove the argument if no files are provided.
+				if len(files) == 0 {
+					continue
+				}
+			}
+		}
+
+		text, err := e.expandCodes(arg.text, files)
+		if err != nil {
+			return nil, err
+		}
+		cmd = append(cmd, text)
+	}
+	if len(cmd) == 0 {
+		return nil, errors.New("empty command")
+	}
+
+	return cmd, nil
+}
+
+// expandCodes expands the field codes contained in the specified argument.
+// The %F, %U and %i field codes are only valid as standalone arguments.
+func (e *Entry) expandCodes(arg string, files []string) (string, error) {
+	var file string
+	if len(files) > 0 {
+		file = files[0]
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(arg); i++ {
+		if arg[i] != '%' {
+			sb.WriteByte(arg[i])
+			continue
+		}
+		if i++; i == len(arg) {
+			return "", errors.New("incomplete field code at the end of argument")
+		}
+
+		switch code := arg[i]; code {
+		case '%':
+			sb.WriteByte('%')
+		case 'f':
+			sb.WriteString(localPath(file))
+		case 'u':
+			sb.WriteString(file)
+		case 'c':
+			sb.WriteString(e.Name)
+		case 'k':
+			sb.WriteString(e.Path)
+		case 'd', 'D', 'n', 'N', 'v', 'm':
+			// Deprecated field codes.
+		case 'F', 'U', 'i':
+			return "", fmt.Errorf("field code %%%c must be a standalone argument", code)
+		default:
+			return "", fmt.Errorf("unknown field code %%%c", code)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// acceptsSingleFile returns true if the specified arguments contain the
+// %f or %u field codes.
+func acceptsSingleFile(args []execArg) bool {
+	for _, arg := range args {
+		for i := 0; i < len(arg.text)-1; i++ {
+			if arg.text[i] != '%' {
+				continue
+			}
+			if i++; arg.text[i] == 'f' || arg.text[i] == 'u' {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// splitExec splits the specified Exec value into arguments, as defined by
+// the Desktop Entry Specification. Arguments are separated by spaces and
+// can be enclosed in double quotes. Inside double quotes, the double quote,
+// backtick, dollar sign and backslash characters must be escaped using a
+// backslash. Outside double quotes, backslashes escape the next character.
+func splitExec(value string) ([]execArg, error) {
+	var (
+		args    []execArg
+		sb      strings.Builder
+		inArg   bool
+		quoted  bool
+		inQuote bool
+	)
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case inQuote:
+			switch {
+			case c == '"':
+				inQuote = false
+			case c == '\\' && i+1 < len(value) && strings.IndexByte("\"`$\\", value[i+1]) >= 0:
+				i++
+				sb.WriteByte(value[i])
+			default:
+				sb.WriteByte(c)
+			}
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, execArg{text: sb.String(), quoted: quoted})
+				sb.Reset()
+				inArg, quoted = false, false
+			}
+		case c == '"':
+			inArg, quoted, inQuote = true, true, true
+		case c == '\\' && i+1 < len(value):
+			i++
+			inArg = true
+			sb.WriteByte(value[i])
+		default:
+			inArg = true
+			sb.WriteByte(c)
+		}
+	}
+	if inQuote {
+		return nil, errors.New("missing closing double quote")
+	}
+	if inArg {
+		args = append(args, execArg{text: sb.String(), quoted: quoted})
+	}
+
+	return args, nil
+}
+
+// localPath converts the specified file:// URL to a local path. Other
+// values are returned unchanged.
+func localPath(file string) string {
+	if !strings.HasPrefix(file, "file:") {
+		return file
+	}
+
+	u, err := url.Parse(file)
+	if err != nil || u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") {
+		return file
+	}
+
+	return u.Path
+}
@@ -0,0 +1,238 @@
+package desktop
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/adrg/xdg/internal/fileutil"
+	"github.com/adrg/xdg/internal/keyfile"
+)
+
+// entryKeys contains the keys of the [Desktop Entry] group exposed as fields
+// of Entry, in the order they are written by Install.
+var entryKeys = []string{
+	"Type", "Version", "Name", "GenericName", "NoDisplay", "Comment", "Icon",
+	"Hidden", "OnlyShowIn", "NotShowIn", "DBusActivatable", "TryExec", "Exec",
+	"Path", "Terminal", "Actions", "MimeType", "Categories", "Implements",
+	"Keywords", "StartupNotify", "StartupWMClass", "URL",
+	"PrefersNonDefaultGPU", "SingleMainWindow",
+}
+
+// InstallDir returns the applications directory desktop entries are
+// installed to by Install. For privileged users (i.e. root), it is the
+// applications directory inside the first directory of xdg.DataDirs.
+// Otherwise, it is the applications directory inside xdg.DataHome.
+func InstallDir() string {
+	if os.Geteuid() == 0 && len(xdg.DataDirs) > 0 {
+		return filepath.Join(xdg.DataDirs[0], "applications")
+	}
+
+	return filepath.Join(xdg.DataHome, "applications")
+}
+
+// Install writes the specified desktop entry to a file named after the ID
+// of the entry (e.g. org.example.App.desktop) inside the directory returned
+// by InstallDir, similar to the xdg-desktop-menu install command. Existing
+// files are replaced. The .desktop extension is appended to the ID, if
+// missing. On success, the ID and the Path of the entry are updated.
+//
+// The fields of the entry are written to the [Desktop Entry] group of the
+// file, along with its actions. For entries parsed from files, the keys
+// which are not exposed as fields of the entry, such as extension keys
+// (X-*), are preserved. The localized values of the Name, GenericName,
+// Comment, Icon and Keywords keys are preserved if the corresponding fields
+// are unchanged, and dropped otherwise.
+//
+// The entry is validated using Entry.Validate before being written and
+// entries with findings of SeverityError are rejected. The findings are
+// included in the returned error.
+//
+// The mimeinfo.cache file of the directory is regenerated using
+// UpdateMimeCache, so that the entry is immediately associated with the
+// MIME types listed by its MimeType field.
+func Install(e *Entry) error {
+	id, err := installID(e.ID)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, finding := range e.Validate() {
+		if finding.Severity == SeverityError {
+			errs = append(errs, finding)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid desktop entry `%s`: %w", id, errors.Join(errs...))
+	}
+
+	dir := InstallDir()
+	if err := os.MkdirAll(dir, os.ModeDir|0o755); err != nil {
+		return err
+	}
+
+	kf := e.keyFile()
+
+	var buf bytes.Buffer
+	if _, err := kf.WriteTo(&buf); err != nil {
+		return err
+	}
+
+	name := filepath.Join(dir, id)
+	if err := fileutil.WriteAtomic(name, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	if err := UpdateMimeCache(dir); err != nil {
+		return err
+	}
+	e.ID, e.Path, e.group = id, name, kf.Group(entryGroup)
+
+	return nil
+}
+
+// Uninstall removes the desktop entry with the specified desktop file ID
+// from the directory returned by InstallDir, similar to the
+// xdg-desktop-menu uninstall command, and regenerates the mimeinfo.cache
+// file of the directory. Returns ErrNotFound if the entry is not installed
+// in the directory.
+func Uninstall(id string) error {
+	id, err := installID(id)
+	if err != nil {
+		return err
+	}
+
+	dir := InstallDir()
+	if err := os.Remove(filepath.Join(dir, id)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: could not locate `%s` in %s", ErrNotFound, id, dir)
+		}
+		return err
+	}
+
+	return UpdateMimeCache(dir)
+}
+
+// installID returns the desktop file ID used to install an entry.
+func installID(id string) (string, error) {
+	if !strings.HasSuffix(id, fileExt) {
+		id += fileExt
+	}
+	if id == fileExt || strings.ContainsAny(id, "/;"+string(filepath.Separator)) || strings.HasPrefix(id, "-") {
+		return "", fmt.Errorf("invalid desktop file ID `%s`", id)
+	}
+
+	return id, nil
+}
+
+// keyFile returns the key file representation of the entry.
+func (e *Entry) keyFile() *keyfile.File {
+	var actions []string
+	for _, action := range e.Actions {
+		actions = append(actions, action.ID)
+	}
+
+	values := map[string]string{
+		"Type":           keyfile.Escape(e.Type),
+		"Version":        keyfile.Escape(e.Version),
+		"Name":           keyfile.Escape(e.Name),
+		"GenericName":    keyfile.Escape(e.GenericName),
+		"Comment":        keyfile.Escape(e.Comment),
+		"Icon":           keyfile.Escape(e.Icon),
+		"OnlyShowIn":     keyfile.JoinList(e.OnlyShowIn),
+		"NotShowIn":      keyfile.JoinList(e.NotShowIn),
+		"TryExec":        keyfile.Escape(e.TryExec),
+		"Exec":           keyfile.Escape(e.Exec),
+		"Path":           keyfile.Escape(e.WorkingDir),
+		"Actions":        keyfile.JoinList(actions),
+		"MimeType":       keyfile.JoinList(e.MimeType),
+		"Categories":     keyfile.JoinList(e.Categories),
+		"Implements":     keyfile.JoinList(e.Implements),
+		"Keywords":       keyfile.JoinList(e.Keywords),
+		"StartupWMClass": keyfile.Escape(e.StartupWMClass),
+		"URL":            keyfile.Escape(e.URL),
+	}
+	bools := map[string]bool{
+		"NoDisplay":            e.NoDisplay,
+		"Hidden":               e.Hidden,
+		"DBusActivatable":      e.DBusActivatable,
+		"Terminal":             e.Terminal,
+		"StartupNotify":        e.StartupNotify,
+		"PrefersNonDefaultGPU": e.PrefersNonDefaultGPU,
+		"SingleMainWindow":     e.SingleMainWindow,
+	}
+	for key, b := range bools {
+		if b {
+			values[key] = strconv.FormatBool(b)
+		}
+	}
+
+	// The localized fields of parsed entries contain the values matching the
+	// locale of the entry. If unchanged, the non-localized values of the
+	// entry are written instead. Otherwise, the localized values of the
+	// fields are dropped, as they no longer match.
+	changed := map[string]bool{}
+	if e.group != nil {
+		parsed := func(key string) string {
+			value, _ := e.group.LocalizedValue(key, e.locale)
+			return value
+		}
+
+		localized := map[string]bool{
+			"Name":        e.Name == keyfile.String(parsed("Name")),
+			"GenericName": e.GenericName == keyfile.String(parsed("GenericName")),
+			"Comment":     e.Comment == keyfile.String(parsed("Comment")),
+			"Icon":        e.Icon == keyfile.String(parsed("Icon")),
+			"Keywords":    slices.Equal(e.Keywords, keyfile.List(parsed("Keywords"))),
+		}
+		for key, unchanged := range localized {
+			if !unchanged {
+				changed[key] = true
+				continue
+			}
+			if value, ok := e.group.Value(key); ok {
+				values[key] = value
+			}
+		}
+	}
+
+	kf := keyfile.New()
+	group := kf.AddGroup(entryGroup)
+	for _, key := range entryKeys {
+		if value := values[key]; value != "" {
+			group.Set(key, value)
+		}
+	}
+
+	// Preserve the localized values and the unknown keys of parsed entries.
+	if e.group != nil {
+		for _, entry := range e.group.Entries() {
+			if entry.Locale == "" && slices.Contains(entryKeys, entry.Key) ||
+				entry.Locale != "" && changed[entry.Key] {
+				continue
+			}
+			if _, ok := group.LocaleValue(entry.Key, entry.Locale); !ok {
+				group.SetLocale(entry.Key, entry.Locale, entry.Value)
+			}
+		}
+	}
+
+	for _, action := range e.Actions {
+		group := kf.AddGroup(actionGroupPrefix + action.ID)
+		group.Set("Name", keyfile.Escape(action.Name))
+		if action.Icon != "" {
+			group.Set("Icon", keyfile.Escape(action.Icon))
+		}
+		if action.Exec != "" {
+			group.Set("Exec", keyfile.Escape(action.Exec))
+		}
+	}
+
+	return kf
+}
@@ -0,0 +1,84 @@
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrg/xdg"
+)
+
+// fileExt is the extension of desktop entry files.
+const fileExt = ".desktop"
+
+// ErrNotFound is returned when a desktop entry cannot be located.
+var ErrNotFound = errors.New("desktop entry not found")
+
+// Lookup returns the desktop entry with the specified desktop file ID,
+// searching the application directories defined by xdg.ApplicationDirs.
+// The .desktop extension is appended to the ID, if missing.
+//
+// As defined by the Desktop Entry Specification, the ID of a desktop entry
+// is its path relative to the application directory containing it, with
+// path separators replaced by dashes (e.g. the ID of
+// /usr/share/applications/kde/okular.desktop is kde-okular.desktop).
+// The first entry matching the ID is returned. Entries marked as hidden
+// are treated as deleted, in which case ErrNotFound is returned.
+func Lookup(id string) (*Entry, error) {
+	if !strings.HasSuffix(id, fileExt) {
+		id += fileExt
+	}
+	if strings.ContainsAny(id, "/"+string(filepath.Separator)) || strings.HasPrefix(id, "-") {
+		return nil, fmt.Errorf("invalid desktop file ID `%s`", id)
+	}
+
+	for _, dir := range xdg.ApplicationDirs {
+		path, ok := findEntry(dir, id)
+		if !ok {
+			continue
+		}
+
+		entry, err := ParseFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Hidden {
+			break
+		}
+		entry.ID = id
+
+		return entry, nil
+	}
+
+	return nil, fmt.Errorf("%w: could not locate `%s` in any of the following paths: %s",
+		ErrNotFound, id, strings.Join(xdg.ApplicationDirs, string(os.PathListSeparator)))
+}
+
+// findEntry returns the path of the desktop entry file with the specified
+// ID inside the specified directory. Each dash of the ID may correspond to
+// a path separator, so the subdirectories matching the prefixes of the ID
+// are searched recursively.
+func findEntry(dir, id string) (string, bool) {
+	path := filepath.Join(dir, id)
+	if fi, err := os.Stat(path); err == nil && fi.Mode().IsRegular() {
+		return path, true
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] != '-' {
+			continue
+		}
+
+		subdir := filepath.Join(dir, id[:i])
+		if fi, err := os.Stat(subdir); err != nil || !fi.IsDir() {
+			continue
+		}
+		if path, ok := findEntry(subdir, id[i+1:]); ok {
+			return path, true
+		}
+	}
+
+	return "", false
+}
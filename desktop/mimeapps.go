@@ -0,0 +1,307 @@
+package desktop
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/adrg/xdg/internal/fileutil"
+	"github.com/adrg/xdg/internal/keyfile"
+	"github.com/adrg/xdg/internal/pathutil"
+)
+
+// Names of the files and groups used for associating applications with
+// MIME types.
+const (
+	mimeAppsFileName  = "mimeapps.list"
+	mimeCacheFileName = "mimeinfo.cache"
+
+	defaultAppsGroup   = "Default Applications"
+	addedAssocsGroup   = "Added Associations"
+	removedAssocsGroup = "Removed Associations"
+	mimeCacheGroup     = "MIME Cache"
+)
+
+// MimeAppsFiles returns the locations of the mimeapps.list files, in order
+// of precedence, as defined by the Association between MIME types and
+// applications specification. For each directory, the desktop specific
+// files (e.g. gnome-mimeapps.list), derived from the current desktop
+// environments returned by CurrentDesktops, take precedence over the
+// mimeapps.list file. The directories are searched in the following order:
+// xdg.ConfigHome, xdg.ConfigDirs, the applications directory inside
+// xdg.DataHome and the applications directories inside xdg.DataDirs.
+// The returned files are not guaranteed to exist.
+func MimeAppsFiles() []string {
+	dirs := append([]string{xdg.ConfigHome}, xdg.ConfigDirs...)
+	dirs = append(dirs, filepath.Join(xdg.DataHome, "applications"))
+	for _, dir := range xdg.DataDirs {
+		dirs = append(dirs, filepath.Join(dir, "applications"))
+	}
+
+	var names []string
+	for _, name := range CurrentDesktops() {
+		names = append(names, strings.ToLower(name)+"-"+mimeAppsFileName)
+	}
+	names = append(names, mimeAppsFileName)
+
+	var files []string
+	for _, dir := range dirs {
+		for _, name := range names {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+
+	return pathutil.Unique(files)
+}
+
+// DefaultApplication returns the default application used to open files of
+// the specified MIME type. The Default Applications groups of the files
+// returned by MimeAppsFiles are searched in order of precedence and the
+// first listed application which is installed is returned. If no default
+// application is found, the most preferred application returned by
+// Applications is returned instead. If there is no application associated
+// with the MIME type, ErrNotFound is returned.
+func DefaultApplication(mimeType string) (*Entry, error) {
+	for _, kf := range mimeAppsFiles() {
+		for _, id := range groupList(kf, defaultAppsGroup, mimeType) {
+			if entry, err := Lookup(id); err == nil {
+				return entry, nil
+			}
+		}
+	}
+
+	entries := associations(mimeType, 1)
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: no application associated with `%s`", ErrNotFound, mimeType)
+	}
+
+	return entries[0], nil
+}
+
+// Applications returns the installed applications associated with the
+// specified MIME type, in order of preference. The default application,
+// if any, is returned first. The applications listed in the Added
+// Associations groups of the files returned by MimeAppsFiles come next,
+// followed by the applications listed in the mimeinfo.cache files of the
+// application directories. The applications listed in the Removed
+// Associations group of a mimeapps.list file are excluded from the
+// associations defined by the files with lower precedence.
+func Applications(mimeType string) ([]*Entry, error) {
+	entries := associations(mimeType, -1)
+	if def, err := DefaultApplication(mimeType); err == nil {
+		entries = slices.DeleteFunc(entries, func(entry *Entry) bool {
+			return entry.ID == def.ID
+		})
+		entries = append([]*Entry{def}, entries...)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: no application associated with `%s`", ErrNotFound, mimeType)
+	}
+
+	return entries, nil
+}
+
+// associations returns at most limit installed applications associated with
+// the specified MIME type, in order of preference. A negative limit returns
+// all the associated applications.
+func associations(mimeType string, limit int) []*Entry {
+	var (
+		entries []*Entry
+		seen    = map[string]bool{}
+		removed = map[string]bool{}
+	)
+	add := func(ids []string) bool {
+		for _, id := range ids {
+			if seen[id] || removed[id] {
+				continue
+			}
+			seen[id] = true
+
+			entry, err := Lookup(id)
+			if err != nil {
+				continue
+			}
+			if entries = append(entries, entry); len(entries) == limit {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, kf := range mimeAppsFiles() {
+		if !add(groupList(kf, addedAssocsGroup, mimeType)) {
+			return entries
+		}
+		for _, id := range groupList(kf, removedAssocsGroup, mimeType) {
+			removed[id] = true
+		}
+	}
+	for _, dir := range xdg.ApplicationDirs {
+		kf, err := keyfile.ParseFile(filepath.Join(dir, mimeCacheFileName))
+		if err != nil {
+			continue
+		}
+		if !add(groupList(kf, mimeCacheGroup, mimeType)) {
+			return entries
+		}
+	}
+
+	return entries
+}
+
+// mimeAppsFiles returns the parsed mimeapps.list files, in order of
+// precedence. Files which cannot be read or parsed are ignored.
+func mimeAppsFiles() []*keyfile.File {
+	var files []*keyfile.File
+	for _, name := range MimeAppsFiles() {
+		if kf, err := keyfile.ParseFile(name); err == nil {
+			files = append(files, kf)
+		}
+	}
+
+	return files
+}
+
+// groupList returns the list value of the specified key of the specified
+// group of the provided key file.
+func groupList(kf *keyfile.File, group, key string) []string {
+	g := kf.Group(group)
+	if g == nil {
+		return nil
+	}
+
+	value, ok := g.Value(key)
+	if !ok {
+		return nil
+	}
+
+	return keyfile.List(value)
+}
+
+// SetDefaultApplication sets the application with the specified desktop
+// file ID as the default application for the specified MIME types
+// (e.g. image/png, x-scheme-handler/https), similar to the xdg-mime default
+// command. The application is also added as the most preferred associated
+// application and it is no longer marked as removed. The changes are made
+// to the mimeapps.list file located in xdg.ConfigHome, which is created if
+// it does not exist. Unrelated entries and comments are preserved.
+func SetDefaultApplication(id string, mimeTypes ...string) error {
+	return editMimeApps(id, mimeTypes, func(kf *keyfile.File, mimeType string) {
+		kf.AddGroup(defaultAppsGroup).Set(mimeType, keyfile.JoinList([]string{id}))
+		prependList(kf, addedAssocsGroup, mimeType, id)
+		removeFromList(kf, removedAssocsGroup, mimeType, id)
+	})
+}
+
+// AddAssociation associates the application with the specified desktop
+// file ID with the specified MIME types, by adding it to the Added
+// Associations group of the mimeapps.list file located in xdg.ConfigHome.
+// The application is no longer marked as removed for the MIME types.
+// See SetDefaultApplication for more details.
+func AddAssociation(id string, mimeTypes ...string) error {
+	return editMimeApps(id, mimeTypes, func(kf *keyfile.File, mimeType string) {
+		appendList(kf, addedAssocsGroup, mimeType, id)
+		removeFromList(kf, removedAssocsGroup, mimeType, id)
+	})
+}
+
+// RemoveAssociation removes the association between the application with
+// the specified desktop file ID and the specified MIME types, by adding it
+// to the Removed Associations group of the mimeapps.list file located in
+// xdg.ConfigHome. The application is also removed from the Default
+// Applications and Added Associations groups of the file, for the specified
+// MIME types. See SetDefaultApplication for more details.
+func RemoveAssociation(id string, mimeTypes ...string) error {
+	return editMimeApps(id, mimeTypes, func(kf *keyfile.File, mimeType string) {
+		removeFromList(kf, defaultAppsGroup, mimeType, id)
+		removeFromList(kf, addedAssocsGroup, mimeType, id)
+		appendList(kf, removedAssocsGroup, mimeType, id)
+	})
+}
+
+// editMimeApps applies the specified edit function for each of the
+// specified MIME types to the mimeapps.list file located in xdg.ConfigHome.
+func editMimeApps(id string, mimeTypes []string, edit func(*keyfile.File, string)) error {
+	if !strings.HasSuffix(id, fileExt) || strings.ContainsAny(id, "/;"+string(filepath.Separator)) {
+		return fmt.Errorf("invalid desktop file ID `%s`", id)
+	}
+	if len(mimeTypes) == 0 {
+		return errors.New("no MIME types specified")
+	}
+	for _, mimeType := range mimeTypes {
+		if !validMimeType(mimeType) {
+			return fmt.Errorf("invalid MIME type `%s`", mimeType)
+		}
+	}
+
+	name := filepath.Join(xdg.ConfigHome, mimeAppsFileName)
+	kf, err := keyfile.ParseFile(name)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err := os.MkdirAll(xdg.ConfigHome, os.ModeDir|0o700); err != nil {
+			return err
+		}
+		kf = keyfile.New()
+	}
+
+	for _, mimeType := range mimeTypes {
+		edit(kf, mimeType)
+	}
+
+	var buf bytes.Buffer
+	if _, err := kf.WriteTo(&buf); err != nil {
+		return err
+	}
+
+	return fileutil.WriteAtomic(name, buf.Bytes(), 0o644)
+}
+
+// validMimeType returns true if the specified MIME type is in the
+// media/subtype format and can be used as a key of mimeapps.list and
+// mimeinfo.cache files.
+func validMimeType(mimeType string) bool {
+	major, minor, ok := strings.Cut(mimeType, "/")
+	return ok && major != "" && minor != "" && !strings.ContainsAny(mimeType, "=[]; \t\n")
+}
+
+// appendList adds the specified item to the end of the list value of the
+// specified key of the specified group, if it is not already included.
+func appendList(kf *keyfile.File, group, key, item string) {
+	items := groupList(kf, group, key)
+	if !slices.Contains(items, item) {
+		kf.AddGroup(group).Set(key, keyfile.JoinList(append(items, item)))
+	}
+}
+
+// prependList moves the specified item to the start of the list value of
+// the specified key of the specified group.
+func prependList(kf *keyfile.File, group, key, item string) {
+	items := slices.DeleteFunc(groupList(kf, group, key), func(s string) bool {
+		return s == item
+	})
+	kf.AddGroup(group).Set(key, keyfile.JoinList(append([]string{item}, items...)))
+}
+
+// removeFromList removes the specified item from the list value of the
+// specified key of the specified group. The key is removed if the list
+// becomes empty.
+func removeFromList(kf *keyfile.File, group, key, item string) {
+	items := groupList(kf, group, key)
+	if !slices.Contains(items, item) {
+		return
+	}
+
+	g := kf.Group(group)
+	if items = slices.DeleteFunc(items, func(s string) bool { return s == item }); len(items) == 0 {
+		g.Delete(key)
+		return
+	}
+	g.Set(key, keyfile.JoinList(items))
+}
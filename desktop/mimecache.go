@@ -0,0 +1,79 @@
+package desktop
+
+import (
+	"bytes"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"github.com/adrg/xdg/internal/fileutil"
+	"github.com/adrg/xdg/internal/keyfile"
+)
+
+// UpdateMimeCache regenerates the mimeinfo.cache file of the specified
+// applications directory (e.g. an entry of xdg.ApplicationDirs), similar to
+// the update-desktop-database utility. The file associates each MIME type
+// with the desktop file IDs of the entries located in the directory and its
+// subdirectories which list the MIME type in their MimeType key. Entries
+// marked as hidden and entries which cannot be parsed are ignored.
+//
+// The mimeinfo.cache files are used by Applications and DefaultApplication
+// in order to avoid parsing all the desktop entries of a directory. The
+// entries of directories without a mimeinfo.cache file are only associated
+// with MIME types through mimeapps.list files.
+func UpdateMimeCache(dir string) error {
+	types, err := scanMimeTypes(dir)
+	if err != nil {
+		return err
+	}
+
+	kf := keyfile.New()
+	group := kf.AddGroup(mimeCacheGroup)
+	for _, mimeType := range slices.Sorted(maps.Keys(types)) {
+		group.Set(mimeType, keyfile.JoinList(types[mimeType]))
+	}
+
+	var buf bytes.Buffer
+	if _, err := kf.WriteTo(&buf); err != nil {
+		return err
+	}
+
+	return fileutil.WriteAtomic(filepath.Join(dir, mimeCacheFileName), buf.Bytes(), 0o644)
+}
+
+// scanMimeTypes returns the sorted desktop file IDs of the entries located
+// in the specified applications directory, indexed by the MIME types listed
+// by their MimeType key.
+func scanMimeTypes(dir string) (map[string][]string, error) {
+	paths, err := entryPaths(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		types = map[string][]string{}
+		seen  = map[string]bool{}
+	)
+	for _, path := range paths {
+		id, ok := entryID(dir, path)
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		entry, err := ParseFile(path)
+		if err != nil || entry.Hidden {
+			continue
+		}
+		for _, mimeType := range entry.MimeType {
+			if validMimeType(mimeType) && !slices.Contains(types[mimeType], id) {
+				types[mimeType] = append(types[mimeType], id)
+			}
+		}
+	}
+	for _, ids := range types {
+		slices.Sort(ids)
+	}
+
+	return types, nil
+}
@@ -0,0 +1,118 @@
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/adrg/xdg/sharedmime"
+)
+
+// OpenCommand returns the command used to open the specified file or URL
+// with the default application, similar to the xdg-open utility. For local
+// files, the MIME type is determined using the shared MIME-info database,
+// based on the name and, if needed, on the content of the file. Directories
+// have the inode/directory MIME type. Relative paths of existing files are
+// resolved against the current working directory. Targets which do not exist
+// as local files are treated as URLs, using the x-scheme-handler/<scheme>
+// MIME type (e.g. x-scheme-handler/https). The file:// URLs are treated as
+// local files.
+//
+// The default application is resolved using DefaultApplication. If there is
+// no application associated with the MIME type of a file, the default
+// applications of the MIME types it is a subclass of are used instead
+// (e.g. text/plain for text/x-go), apart from application/octet-stream.
+//
+// Applications which must be run in a terminal are run using the terminal
+// emulator specified by the TERMINAL environment variable or, if it is not
+// set, using x-terminal-emulator, which is only available on Debian based
+// distributions. The terminal emulator is expected to accept the -e option,
+// followed by the command to run. The returned command can be adjusted for
+// terminal emulators which do not follow this convention.
+func OpenCommand(target string) (*exec.Cmd, error) {
+	// Existing files take precedence over URLs (e.g. a file named foo:bar).
+	// The paths are made absolute, as the command might be run in the working
+	// directory of the application.
+	local := false
+	if _, err := os.Stat(target); err == nil {
+		if target, err = filepath.Abs(target); err != nil {
+			return nil, err
+		}
+		local = true
+	}
+
+	mimeTypes, err := targetMimeTypes(target, local)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry *Entry
+	for _, mimeType := range mimeTypes {
+		if entry, err = DefaultApplication(mimeType); !errors.Is(err, ErrNotFound) {
+			break
+		}
+	}
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("%w: no application associated with `%s`", ErrNotFound, mimeTypes[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cmds, err := entry.Command(target)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", entry.ID, err)
+	}
+	args := cmds[0]
+
+	if entry.Terminal {
+		terminal := os.Getenv("TERMINAL")
+		if terminal == "" {
+			terminal = "x-terminal-emulator"
+		}
+		args = append([]string{terminal, "-e"}, args...)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = entry.WorkingDir
+	return cmd, nil
+}
+
+// targetMimeTypes returns the MIME type of the specified file or URL,
+// followed by the MIME types it is a subclass of, in breadth-first order.
+// The local parameter specifies whether the target is an existing file.
+func targetMimeTypes(target string, local bool) ([]string, error) {
+	if u, err := url.Parse(target); !local && err == nil && len(u.Scheme) > 1 {
+		if u.Scheme != "file" {
+			return []string{"x-scheme-handler/" + strings.ToLower(u.Scheme)}, nil
+		}
+		target = localPath(target)
+	}
+
+	db, err := sharedmime.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	mimeType, err := db.TypeByFile(target)
+	if err != nil {
+		return nil, err
+	}
+
+	mimeTypes := []string{db.Canonical(mimeType)}
+	for i := 0; i < len(mimeTypes); i++ {
+		for _, parent := range db.Parents(mimeTypes[i]) {
+			if parent = db.Canonical(parent); parent != "application/octet-stream" &&
+				!slices.Contains(mimeTypes, parent) {
+				mimeTypes = append(mimeTypes, parent)
+			}
+		}
+	}
+
+	return mimeTypes, nil
+}
@@ -0,0 +1,673 @@
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adrg/xdg/internal/keyfile"
+)
+
+// Severity represents the severity of a validation finding.
+type Severity int
+
+// Validation finding severities.
+const (
+	// SeverityError indicates that the desktop entry does not conform to the
+	// Desktop Entry Specification and may not work as expected.
+	SeverityError Severity = iota
+
+	// SeverityWarning indicates the use of deprecated or unregistered
+	// features, which may not be supported by all desktop environments.
+	SeverityWarning
+
+	// SeverityHint indicates a possible improvement of the desktop entry.
+	SeverityHint
+)
+
+// String returns a textual representation of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityHint:
+		return "hint"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// Finding represents a problem found while validating a desktop entry.
+type Finding struct {
+	// Severity contains the severity of the problem.
+	Severity Severity
+
+	// Line contains the line number the problem was found at, starting from 1.
+	// It is zero for problems which are not related to a specific line or
+	// which were found in entries not parsed from files.
+	Line int
+
+	// Group contains the name of the group the problem was found in, if any.
+	Group string
+
+	// Key contains the key the problem was found in, if any.
+	Key string
+
+	// Msg describes the problem.
+	Msg string
+}
+
+// Error returns a textual representation of the finding.
+func (f Finding) Error() string {
+	if f.Line == 0 {
+		return fmt.Sprintf("%s: %s", f.Severity, f.Msg)
+	}
+
+	return fmt.Sprintf("line %d: %s: %s", f.Line, f.Severity, f.Msg)
+}
+
+// valueType represents the type of the value of a desktop entry key.
+type valueType int
+
+const (
+	stringValue valueType = iota
+	localeStringValue
+	iconStringValue
+	booleanValue
+	stringListValue
+	localeStringListValue
+)
+
+var (
+	// entryKeyTypes contains the value types of the keys of the
+	// [Desktop Entry] group.
+	entryKeyTypes = map[string]valueType{
+		"Type":                 stringValue,
+		"Version":              stringValue,
+		"Name":                 localeStringValue,
+		"GenericName":          localeStringValue,
+		"NoDisplay":            booleanValue,
+		"Comment":              localeStringValue,
+		"Icon":                 iconStringValue,
+		"Hidden":               booleanValue,
+		"OnlyShowIn":           stringListValue,
+		"NotShowIn":            stringListValue,
+		"DBusActivatable":      booleanValue,
+		"TryExec":              stringValue,
+		"Exec":                 stringValue,
+		"Path":                 stringValue,
+		"Terminal":             booleanValue,
+		"Actions":              stringListValue,
+		"MimeType":             stringListValue,
+		"Categories":           stringListValue,
+		"Implements":           stringListValue,
+		"Keywords":             localeStringListValue,
+		"StartupNotify":        booleanValue,
+		"StartupWMClass":       stringValue,
+		"URL":                  stringValue,
+		"PrefersNonDefaultGPU": booleanValue,
+		"SingleMainWindow":     booleanValue,
+	}
+
+	// actionKeyTypes contains the value types of the keys of the
+	// [Desktop Action <id>] groups.
+	actionKeyTypes = map[string]valueType{
+		"Name": localeStringValue,
+		"Icon": iconStringValue,
+		"Exec": stringValue,
+	}
+
+	// applicationKeys contains the keys which are only valid for entries of
+	// the Application type.
+	applicationKeys = []string{
+		"DBusActivatable", "TryExec", "Exec", "Path", "Terminal", "Actions",
+		"MimeType", "Categories", "Keywords", "StartupNotify", "StartupWMClass",
+		"PrefersNonDefaultGPU", "SingleMainWindow",
+	}
+
+	// deprecatedKeys contains the keys which are no longer part of the
+	// Desktop Entry Specification.
+	deprecatedKeys = []string{
+		"Encoding", "MiniIcon", "TerminalOptions", "Protocols", "Extensions",
+		"BinaryPattern", "MapNotify", "SwallowTitle", "SwallowExec",
+		"SortOrder", "FilePattern",
+	}
+
+	// deprecatedTypes contains the entry types which are no longer part of
+	// the Desktop Entry Specification.
+	deprecatedTypes = []string{"MimeType", "ServiceType", "Service", "FSDevice"}
+
+	// knownVersions contains the versions of the Desktop Entry Specification.
+	knownVersions = []string{"1.0", "1.1", "1.2", "1.3", "1.4", "1.5"}
+
+	// registeredDesktops contains the registered desktop environment names,
+	// used by the OnlyShowIn and NotShowIn keys.
+	registeredDesktops = []string{
+		"GNOME", "GNOME-Classic", "GNOME-Flashback", "KDE", "LXDE", "LXQt",
+		"MATE", "Razor", "ROX", "TDE", "Unity", "XFCE", "EDE", "Cinnamon",
+		"Pantheon", "Budgie", "Enlightenment", "DDE", "Endless", "Old",
+	}
+
+	// mainCategories contains the main categories defined by the Desktop
+	// Menu Specification.
+	mainCategories = []string{
+		"AudioVideo", "Audio", "Video", "Development", "Education", "Game",
+		"Graphics", "Network", "Office", "Science", "Settings", "System",
+		"Utility",
+	}
+
+	// additionalCategories contains the additional categories defined by the
+	// Desktop Menu Specification.
+	additionalCategories = []string{
+		"Building", "Debugger", "IDE", "GUIDesigner", "Profiling",
+		"RevisionControl", "Translation", "Calendar", "ContactManagement",
+		"Database", "Dictionary", "Chart", "Email", "Finance", "FlowChart",
+		"PDA", "ProjectManagement", "Presentation", "Spreadsheet",
+		"WordProcessor", "2DGraphics", "VectorGraphics", "RasterGraphics",
+		"3DGraphics", "Scanning", "OCR", "Photography", "Publishing", "Viewer",
+		"TextTools", "DesktopSettings", "HardwareSettings", "Printing",
+		"PackageManager", "Dialup", "InstantMessaging", "Chat", "IRCClient",
+		"Feed", "FileTransfer", "HamRadio", "News", "P2P", "RemoteAccess",
+		"Telephony", "TelephonyTools", "VideoConference", "WebBrowser",
+		"WebDevelopment", "Midi", "Mixer", "Sequencer", "Tuner", "TV",
+		"AudioVideoEditing", "Player", "Recorder", "DiscBurning", "ActionGame",
+		"AdventureGame", "ArcadeGame", "BoardGame", "BlocksGame", "CardGame",
+		"KidsGame", "LogicGame", "RolePlaying", "Shooter", "Simulation",
+		"SportsGame", "StrategyGame", "Art", "Construction", "Music",
+		"Languages", "ArtificialIntelligence", "Astronomy", "Biology",
+		"Chemistry", "ComputerScience", "DataVisualization", "Economy",
+		"Electricity", "Geography", "Geology", "Geoscience", "History",
+		"Humanities", "ImageProcessing", "Literature", "Maps", "Math",
+		"NumericalAnalysis", "MedicalSoftware", "Physics", "Robotics",
+		"Spirituality", "Sports", "ParallelComputing", "Amusement", "Archiving",
+		"Compression", "Electronics", "Emulator", "Engineering", "FileTools",
+		"FileManager", "TerminalEmulator", "Filesystem", "Monitor", "Security",
+		"Accessibility", "Calculator", "Clock", "TextEditor", "Documentation",
+		"Adult", "Core", "KDE", "GNOME", "XFCE", "DDE", "GTK", "Qt", "Motif",
+		"Java", "ConsoleOnly",
+	}
+
+	// reservedCategories contains the categories which can only be used
+	// along with the OnlyShowIn key.
+	reservedCategories = []string{"Screensaver", "TrayIcon", "Applet", "Shell"}
+
+	keyRegexp    = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+	localeRegexp = regexp.MustCompile(`^[a-z]{2,3}(_([A-Z]{2}|[0-9]{3}))?(\.[A-Za-z0-9_-]+)?(@[A-Za-z0-9_-]+)?$`)
+)
+
+// ValidateFile validates the desktop entry file at the specified location.
+// See Validate for more details.
+func ValidateFile(name string) ([]Finding, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	return Validate(f)
+}
+
+// Validate validates the desktop entry contained in the provided reader
+// against the Desktop Entry Specification, similar to the
+// desktop-file-validate utility. The returned findings describe the problems
+// found, along with their severity and line number. Desktop entries which
+// produce no findings of SeverityError are valid. An error is returned only
+// if the content cannot be read.
+//
+// The structure of the file, the required keys for each entry type, the
+// value types of the keys, the syntax of localized keys, the quoting rules
+// of the Exec key, the categories, the desktop environment names and the
+// actions of the entry are validated. Deprecated keys and values are
+// reported as warnings.
+func Validate(r io.Reader) ([]Finding, error) {
+	kf, err := keyfile.Parse(r)
+	if err != nil {
+		var errs []error
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = joined.Unwrap()
+		}
+
+		var findings []Finding
+		for _, err := range errs {
+			var syntaxErr *keyfile.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				return nil, err
+			}
+			findings = append(findings, Finding{Severity: SeverityError, Line: syntaxErr.Line, Msg: syntaxErr.Msg})
+		}
+		if len(findings) == 0 {
+			return nil, err
+		}
+
+		return findings, nil
+	}
+
+	return validate(kf), nil
+}
+
+// Validate validates the entry against the Desktop Entry Specification.
+// The entry is validated as it would be written by Install, so the line
+// numbers of the returned findings are zero. See Validate for more details.
+func (e *Entry) Validate() []Finding {
+	return validate(e.keyFile())
+}
+
+// validator accumulates the findings of a validation.
+type validator struct {
+	findings []Finding
+}
+
+func (v *validator) add(severity Severity, line int, group, key, format string, args ...any) {
+	v.findings = append(v.findings, Finding{
+		Severity: severity,
+		Line:     line,
+		Group:    group,
+		Key:      key,
+		Msg:      fmt.Sprintf(format, args...),
+	})
+}
+
+// validate validates the provided desktop entry key file.
+func validate(kf *keyfile.File) []Finding {
+	v := &validator{}
+
+	groups := kf.Groups()
+	if len(groups) == 0 || groups[0].Name != entryGroup {
+		v.add(SeverityError, 0, "", "", "first group must be [%s]", entryGroup)
+	}
+
+	var (
+		entry   *keyfile.Group
+		actions []*keyfile.Group
+		seen    = map[string]bool{}
+	)
+	for _, group := range groups {
+		if seen[group.Name] {
+			v.add(SeverityError, group.Line, group.Name, "", "duplicate group [%s]", group.Name)
+			continue
+		}
+		seen[group.Name] = true
+
+		switch {
+		case group.Name == entryGroup:
+			entry = group
+		case strings.HasPrefix(group.Name, actionGroupPrefix):
+			actions = append(actions, group)
+		case strings.HasPrefix(group.Name, "X-"):
+		default:
+			v.add(SeverityError, group.Line, group.Name, "",
+				"unknown group [%s]: extension groups must start with X-", group.Name)
+		}
+	}
+	if entry == nil {
+		if len(groups) > 0 {
+			v.add(SeverityError, 0, "", "", "missing [%s] group", entryGroup)
+		}
+		return v.findings
+	}
+	v.validateEntry(entry, actions)
+
+	slices.SortStableFunc(v.findings, func(a, b Finding) int {
+		return a.Line - b.Line
+	})
+	return v.findings
+}
+
+// validateEntry validates the [Desktop Entry] group and the action groups
+// of a desktop entry.
+func (v *validator) validateEntry(group *keyfile.Group, actions []*keyfile.Group) {
+	v.validateKeys(group, entryKeyTypes)
+
+	values := map[string]*keyfile.Entry{}
+	for _, entry := range group.Entries() {
+		if _, ok := values[entry.Key]; !ok && entry.Locale == "" {
+			values[entry.Key] = entry
+		}
+	}
+	value := func(key string) string {
+		if entry, ok := values[key]; ok {
+			return keyfile.String(entry.Value)
+		}
+		return ""
+	}
+	list := func(key string) []string {
+		if entry, ok := values[key]; ok {
+			return keyfile.List(entry.Value)
+		}
+		return nil
+	}
+
+	// Validate required keys.
+	for _, key := range []string{"Type", "Name"} {
+		if entry, ok := values[key]; !ok {
+			v.add(SeverityError, group.Line, group.Name, key, "missing required key %s", key)
+		} else if value(key) == "" {
+			v.add(SeverityError, entry.Line, group.Name, key, "empty value of required key %s", key)
+		}
+	}
+
+	entryType := value("Type")
+	dbusActivatable, _ := keyfile.Bool(value("DBusActivatable"))
+	switch {
+	case entryType == TypeApplication:
+		if _, ok := values["Exec"]; !ok && !dbusActivatable {
+			v.add(SeverityError, group.Line, group.Name, "Exec",
+				"missing required key Exec for entries of type %s which are not D-Bus activatable", entryType)
+		}
+	case entryType == TypeLink:
+		if _, ok := values["URL"]; !ok {
+			v.add(SeverityError, group.Line, group.Name, "URL", "missing required key URL for entries of type %s", entryType)
+		}
+	case entryType == TypeDirectory || entryType == "":
+	case slices.Contains(deprecatedTypes, entryType):
+		v.add(SeverityWarning, values["Type"].Line, group.Name, "Type", "deprecated type `%s`", entryType)
+	default:
+		v.add(SeverityError, values["Type"].Line, group.Name, "Type", "unknown type `%s`", entryType)
+	}
+
+	// Validate keys which are only valid for specific types.
+	if entryType != "" && entryType != TypeApplication {
+		for _, key := range applicationKeys {
+			if entry, ok := values[key]; ok {
+				v.add(SeverityWarning, entry.Line, group.Name, key,
+					"key %s is only valid for entries of type %s", key, TypeApplication)
+			}
+		}
+	}
+	if entry, ok := values["URL"]; ok && entryType != "" && entryType != TypeLink {
+		v.add(SeverityWarning, entry.Line, group.Name, "URL", "key URL is only valid for entries of type %s", TypeLink)
+	}
+
+	// Validate the values of specific keys.
+	if entry, ok := values["Version"]; ok && !slices.Contains(knownVersions, value("Version")) {
+		v.add(SeverityHint, entry.Line, group.Name, "Version", "unknown specification version `%s`", value("Version"))
+	}
+	for _, key := range []string{"Comment", "GenericName"} {
+		if entry, ok := values[key]; ok && value("Name") != "" && strings.EqualFold(value(key), value("Name")) {
+			v.add(SeverityWarning, entry.Line, group.Name, key, "value of key %s is the same as the value of key Name", key)
+		}
+	}
+	if entry, ok := values["Exec"]; ok {
+		v.validateExec(entry, group.Name)
+	}
+	if _, ok := values["OnlyShowIn"]; ok {
+		if entry, ok := values["NotShowIn"]; ok {
+			v.add(SeverityError, entry.Line, group.Name, "NotShowIn", "keys OnlyShowIn and NotShowIn must not be used together")
+		}
+	}
+	for _, key := range []string{"OnlyShowIn", "NotShowIn"} {
+		for _, desktop := range list(key) {
+			if !slices.Contains(registeredDesktops, desktop) && !strings.HasPrefix(desktop, "X-") {
+				v.add(SeverityWarning, values[key].Line, group.Name, key, "unregistered desktop environment `%s`", desktop)
+			}
+		}
+	}
+	for _, mimeType := range list("MimeType") {
+		if !validMimeType(mimeType) {
+			v.add(SeverityError, values["MimeType"].Line, group.Name, "MimeType", "invalid MIME type `%s`", mimeType)
+		}
+	}
+	if _, ok := values["Categories"]; ok {
+		v.validateCategories(values["Categories"], group.Name, list("Categories"), list("OnlyShowIn"))
+	}
+
+	// Validate actions.
+	listed := list("Actions")
+	for _, id := range listed {
+		if !keyRegexp.MatchString(id) {
+			v.add(SeverityError, values["Actions"].Line, group.Name, "Actions", "invalid action identifier `%s`", id)
+			continue
+		}
+
+		i := slices.IndexFunc(actions, func(action *keyfile.Group) bool {
+			return action.Name == actionGroupPrefix+id
+		})
+		if i < 0 {
+			v.add(SeverityError, values["Actions"].Line, group.Name, "Actions",
+				"missing group [%s%s] for action `%s`", actionGroupPrefix, id, id)
+			continue
+		}
+
+		action := actions[i]
+		v.validateKeys(action, actionKeyTypes)
+		if entry := actionEntry(action, "Name"); entry == nil {
+			v.add(SeverityError, action.Line, action.Name, "Name", "missing required key Name")
+		} else if keyfile.String(entry.Value) == "" {
+			v.add(SeverityError, entry.Line, action.Name, "Name", "empty value of required key Name")
+		}
+		if entry := actionEntry(action, "Exec"); entry != nil {
+			v.validateExec(entry, action.Name)
+		} else if !dbusActivatable {
+			v.add(SeverityError, action.Line, action.Name, "Exec",
+				"missing required key Exec for actions of entries which are not D-Bus activatable")
+		}
+	}
+	for _, action := range actions {
+		if id := strings.TrimPrefix(action.Name, actionGroupPrefix); !slices.Contains(listed, id) {
+			v.add(SeverityError, action.Line, action.Name, "", "action `%s` is not listed by key Actions", id)
+		}
+	}
+}
+
+// validateKeys validates the keys of the specified group, along with the
+// types of their values.
+func (v *validator) validateKeys(group *keyfile.Group, keyTypes map[string]valueType) {
+	seen := map[string]bool{}
+	for _, entry := range group.Entries() {
+		key := entry.Key
+		if entry.Locale != "" {
+			key += "[" + entry.Locale + "]"
+		}
+		if seen[key] {
+			v.add(SeverityError, entry.Line, group.Name, entry.Key, "duplicate key %s", key)
+			continue
+		}
+		seen[key] = true
+
+		if !keyRegexp.MatchString(entry.Key) {
+			v.add(SeverityError, entry.Line, group.Name, entry.Key,
+				"invalid key %s: keys must contain only the A-Za-z0-9- characters", entry.Key)
+			continue
+		}
+
+		typ, known := keyTypes[entry.Key]
+		switch {
+		case known:
+		case slices.Contains(deprecatedKeys, entry.Key):
+			v.add(SeverityWarning, entry.Line, group.Name, entry.Key, "deprecated key %s", entry.Key)
+			continue
+		case strings.HasPrefix(entry.Key, "X-"):
+			continue
+		default:
+			v.add(SeverityError, entry.Line, group.Name, entry.Key, "unknown key %s", entry.Key)
+			continue
+		}
+
+		if entry.Locale != "" {
+			if typ != localeStringValue && typ != iconStringValue && typ != localeStringListValue {
+				v.add(SeverityError, entry.Line, group.Name, entry.Key, "key %s cannot be localized", entry.Key)
+				continue
+			}
+
+			switch {
+			case !localeRegexp.MatchString(entry.Locale):
+				v.add(SeverityError, entry.Line, group.Name, entry.Key,
+					"invalid locale `%s`: locales must have the lang_COUNTRY@MODIFIER format", entry.Locale)
+				continue
+			case strings.Contains(entry.Locale, "."):
+				v.add(SeverityWarning, entry.Line, group.Name, entry.Key,
+					"locale `%s` should not contain an encoding", entry.Locale)
+			}
+		}
+
+		v.validateValue(entry, group.Name, typ)
+	}
+}
+
+// validateValue validates the value of the specified entry, based on the
+// specified value type.
+func (v *validator) validateValue(entry *keyfile.Entry, group string, typ valueType) {
+	isList := typ == stringListValue || typ == localeStringListValue
+	for i := 0; i < len(entry.Value); i++ {
+		if entry.Value[i] != '\\' {
+			continue
+		}
+		if i++; i == len(entry.Value) || !strings.ContainsRune(`sntr\`, rune(entry.Value[i])) &&
+			(!isList || entry.Value[i] != ';') {
+			v.add(SeverityError, entry.Line, group, entry.Key, "invalid escape sequence in value of key %s", entry.Key)
+			break
+		}
+	}
+
+	switch typ {
+	case booleanValue:
+		switch entry.Value {
+		case "true", "false":
+		case "0", "1":
+			v.add(SeverityWarning, entry.Line, group, entry.Key,
+				"deprecated boolean value `%s` for key %s: use true or false", entry.Value, entry.Key)
+		default:
+			v.add(SeverityError, entry.Line, group, entry.Key,
+				"invalid boolean value `%s` for key %s", entry.Value, entry.Key)
+		}
+	case stringValue, stringListValue:
+		for _, c := range []byte(entry.Value) {
+			if c < 0x20 || c > 0x7e {
+				v.add(SeverityError, entry.Line, group, entry.Key,
+					"value of key %s must contain only printable ASCII characters", entry.Key)
+				break
+			}
+		}
+	default:
+		if !utf8.ValidString(entry.Value) {
+			v.add(SeverityError, entry.Line, group, entry.Key, "value of key %s must be valid UTF-8", entry.Key)
+		}
+	}
+
+	if typ == iconStringValue {
+		icon := keyfile.String(entry.Value)
+		if ext := filepath.Ext(icon); !filepath.IsAbs(icon) && slices.Contains([]string{".png", ".svg", ".xpm"}, ext) {
+			v.add(SeverityWarning, entry.Line, group, entry.Key,
+				"icon name `%s` should not contain the %s extension", icon, ext)
+		}
+	}
+}
+
+// validateExec validates the quoting rules and the field codes of the
+// specified Exec entry.
+func (v *validator) validateExec(entry *keyfile.Entry, group string) {
+	value := keyfile.String(entry.Value)
+	if strings.TrimSpace(value) == "" {
+		v.add(SeverityError, entry.Line, group, entry.Key, "empty value for key %s", entry.Key)
+		return
+	}
+
+	var (
+		inQuote   bool
+		fileCodes int
+	)
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case inQuote && c == '"':
+			inQuote = false
+		case inQuote && c == '\\':
+			if i+1 == len(value) || !strings.ContainsRune("\"`$\\", rune(value[i+1])) {
+				v.add(SeverityError, entry.Line, group, entry.Key,
+					"invalid escape sequence in quoted argument of key %s", entry.Key)
+				return
+			}
+			i++
+		case inQuote && (c == '`' || c == '$'):
+			v.add(SeverityError, entry.Line, group, entry.Key,
+				"character %c must be escaped in quoted argument of key %s", c, entry.Key)
+			return
+		case inQuote && c == '%':
+			v.add(SeverityWarning, entry.Line, group, entry.Key,
+				"field codes should not be used inside quoted arguments of key %s", entry.Key)
+			return
+		case c == '"':
+			inQuote = true
+		case strings.IndexByte("'\\><~|&;$*?#()`", c) >= 0:
+			v.add(SeverityError, entry.Line, group, entry.Key,
+				"reserved character %c must be quoted in value of key %s", c, entry.Key)
+			return
+		case c == '%':
+			if i++; i == len(value) {
+				v.add(SeverityError, entry.Line, group, entry.Key, "incomplete field code in value of key %s", entry.Key)
+				return
+			}
+
+			switch code := value[i]; code {
+			case '%', 'i', 'c', 'k':
+			case 'f', 'F', 'u', 'U':
+				fileCodes++
+			case 'd', 'D', 'n', 'N', 'v', 'm':
+				v.add(SeverityWarning, entry.Line, group, entry.Key,
+					"deprecated field code %%%c in value of key %s", code, entry.Key)
+			default:
+				v.add(SeverityError, entry.Line, group, entry.Key,
+					"unknown field code %%%c in value of key %s", code, entry.Key)
+				return
+			}
+		}
+	}
+	if inQuote {
+		v.add(SeverityError, entry.Line, group, entry.Key, "missing closing double quote in value of key %s", entry.Key)
+		return
+	}
+	if fileCodes > 1 {
+		v.add(SeverityError, entry.Line, group, entry.Key,
+			"value of key %s must contain at most one of the %%f, %%F, %%u and %%U field codes", entry.Key)
+	}
+
+	if _, err := (&Entry{}).expandExec(value, nil); err != nil {
+		v.add(SeverityError, entry.Line, group, entry.Key, "%v", err)
+	}
+}
+
+// validateCategories validates the categories of a desktop entry.
+func (v *validator) validateCategories(entry *keyfile.Entry, group string, categories, onlyShowIn []string) {
+	hasMain := false
+	for i, category := range categories {
+		switch {
+		case slices.Contains(categories[:i], category):
+			v.add(SeverityWarning, entry.Line, group, entry.Key, "duplicate category `%s`", category)
+		case slices.Contains(mainCategories, category):
+			hasMain = true
+		case slices.Contains(additionalCategories, category), strings.HasPrefix(category, "X-"):
+		case slices.Contains(reservedCategories, category):
+			if len(onlyShowIn) == 0 {
+				v.add(SeverityError, entry.Line, group, entry.Key,
+					"reserved category `%s` can only be used along with key OnlyShowIn", category)
+			}
+		default:
+			v.add(SeverityError, entry.Line, group, entry.Key, "unknown category `%s`", category)
+		}
+	}
+	if !hasMain {
+		v.add(SeverityHint, entry.Line, group, entry.Key, "no main category is listed by key %s", entry.Key)
+	}
+}
+
+// actionEntry returns the non-localized entry of the specified action group
+// key, if it exists.
+func actionEntry(group *keyfile.Group, key string) *keyfile.Entry {
+	for _, entry := range group.Entries() {
+		if entry.Key == key && entry.Locale == "" {
+			return entry
+		}
+	}
+
+	return nil
+}
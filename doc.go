@@ -100,6 +100,11 @@ XDG user directories
 		log.Println("Videos directory:", xdg.UserDirs.Videos)
 		log.Println("Templates directory:", xdg.UserDirs.Templates)
 		log.Println("Public directory:", xdg.UserDirs.PublicShare)
+
+		// Custom user directories (e.g. XDG_PROJECTS_DIR).
+		if dir, ok := xdg.UserDirs.Lookup("PROJECTS"); ok {
+			log.Println("Projects directory:", dir)
+		}
 	}
 */
 package xdg
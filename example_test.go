@@ -2,6 +2,8 @@ package xdg_test
 
 import (
 	"fmt"
+	"log/slog"
+	"path/filepath"
 
 	"github.com/adrg/xdg"
 )
@@ -95,3 +97,91 @@ func ExampleSearchRuntimeFile() {
 
 	fmt.Println("The runtime file was found at:", runtimeFilePath)
 }
+
+func ExampleLogFile() {
+	logFile, err := xdg.LogFile("appname/app.log", &xdg.LogOptions{
+		MaxSize:    5 << 20,
+		MaxBackups: 3,
+		Compress:   true,
+	})
+	if err != nil {
+		// Treat error.
+	}
+	defer func() {
+		_ = logFile.Close()
+	}()
+
+	logger := slog.New(slog.NewTextHandler(logFile, nil))
+	logger.Info("Log file opened")
+}
+
+func ExampleHistoryFile() {
+	history, err := xdg.HistoryFile("appname/history", nil)
+	if err != nil {
+		// Treat error.
+	}
+	if err := history.Append("git status"); err != nil {
+		// Treat error.
+	}
+
+	entries, err := history.Backward()
+	if err != nil {
+		// Treat error.
+	}
+	for _, entry := range entries {
+		fmt.Println("History entry:", entry)
+	}
+}
+
+func ExampleWatchConfigFile() {
+	watcher, err := xdg.WatchConfigFile("appname/app.yaml")
+	if err != nil {
+		// Treat error.
+	}
+	defer func() {
+		_ = watcher.Close()
+	}()
+
+	for {
+		select {
+		case event := <-watcher.Events():
+			fmt.Println("Config file change:", event.Op, event.Path)
+		case err := <-watcher.Errors():
+			fmt.Println("Config file watch error:", err)
+		}
+	}
+}
+
+func ExampleWatchUserDirs() {
+	watcher, err := xdg.WatchUserDirs()
+	if err != nil {
+		// Treat error.
+	}
+	defer func() {
+		_ = watcher.Close()
+	}()
+
+	watcher.Subscribe(func(dirs xdg.UserDirectories) {
+		fmt.Println("Download directory changed:", dirs.Download)
+	})
+}
+
+func ExampleSetUserDirs() {
+	err := xdg.SetUserDirs(map[string]string{
+		"DOWNLOAD": filepath.Join(xdg.Home, "Incoming"),
+	})
+	if err != nil {
+		// Treat error.
+	}
+
+	fmt.Println("Download directory:", xdg.UserDirs.Download)
+}
+
+func ExampleEnsureUserDir() {
+	downloadDir, err := xdg.EnsureUserDir("DOWNLOAD")
+	if err != nil {
+		// Treat error.
+	}
+
+	fmt.Println("Save downloaded files in:", downloadDir)
+}
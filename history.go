@@ -0,0 +1,29 @@
+package xdg
+
+import "github.com/adrg/xdg/internal/history"
+
+// History provides access to a history file, which stores a list of entries
+// (e.g. commands, recently used items) in chronological order. Entries can be
+// safely appended to the same history file by multiple processes concurrently.
+type History = history.History
+
+// HistoryOptions defines the settings of history files opened using
+// HistoryFile.
+type HistoryOptions = history.Options
+
+// defaultHistoryOptions defines the settings used by HistoryFile if no
+// options are provided.
+var defaultHistoryOptions = HistoryOptions{
+	MaxEntries: 1000,
+	Dedupe:     true,
+}
+
+// HistoryFile returns a history backed by the specified file, in a suitable
+// location relative to the state directory. The relPath parameter must contain
+// the name of the history file, and optionally, a set of parent directories
+// (e.g. appname/history). If the specified directories do not exist, they will
+// be created relative to the base state directory. If opts is nil, the last
+// 1000 entries are retained and duplicate entries are removed.
+func HistoryFile(relPath string, opts *HistoryOptions) (*History, error) {
+	return baseDirs.historyFile(relPath, opts)
+}
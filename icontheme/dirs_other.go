@@ -0,0 +1,7 @@
+//go:build darwin || plan9 || windows
+
+package icontheme
+
+// pixmapsDirs contains the legacy icon directories searched after the icon
+// theme base directories.
+var pixmapsDirs []string
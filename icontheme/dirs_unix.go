@@ -0,0 +1,7 @@
+//go:build aix || dragonfly || freebsd || (js && wasm) || nacl || linux || netbsd || openbsd || solaris
+
+package icontheme
+
+// pixmapsDirs contains the legacy icon directories searched after the icon
+// theme base directories.
+var pixmapsDirs = []string{"/usr/share/pixmaps"}
@@ -0,0 +1,47 @@
+/*
+Package icontheme provides an implementation of the Icon Theme Specification.
+Icon themes, installed in the icons subdirectories of the XDG data directories,
+provide the icons referenced by name in desktop entries and MIME type
+definitions. The package locates the icon files which best match a requested
+size and scale, following the inheritance of the themes.
+
+	For more information regarding the Icon Theme Specification see:
+	https://specifications.freedesktop.org/icon-theme-spec/latest/
+
+# Usage
+
+	package main
+
+	import (
+		"fmt"
+		"log"
+
+		"github.com/adrg/xdg/icontheme"
+	)
+
+	func main() {
+		// Locate a 48x48 icon, using the Adwaita theme.
+		path, err := icontheme.Lookup("Adwaita", "firefox", 48, 1)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(path)
+
+		// Load an icon theme and list its directories.
+		theme, err := icontheme.LoadTheme("hicolor")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(theme.Name, theme.Inherits)
+
+		for _, dir := range theme.Directories {
+			fmt.Println(dir.Path, dir.Size, dir.Type)
+		}
+
+		// Install an application icon in the user's hicolor theme.
+		if err := icontheme.InstallIcon("assets/myapp.png", "myapp", 48, 128); err != nil {
+			log.Fatal(err)
+		}
+	}
+*/
+package icontheme
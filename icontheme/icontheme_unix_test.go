@@ -0,0 +1,286 @@
+//go:build aix || dragonfly || freebsd || (js && wasm) || nacl || linux || netbsd || openbsd || solaris
+
+package icontheme_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adrg/xdg"
+	"github.com/adrg/xdg/icontheme"
+)
+
+const testThemeIndex = `[Icon Theme]
+Name=Test
+Name[fr]=Essai
+Comment=Test theme
+Inherits=Parent
+Example=folder
+Directories=16x16/apps,48x48/apps,scalable/apps,invalid/apps,
+ScaledDirectories=24x24@2/apps
+
+[16x16/apps]
+Size=16
+Context=Applications
+Type=Fixed
+
+[48x48/apps]
+Size=48
+Type=Fixed
+
+[24x24@2/apps]
+Size=24
+Scale=2
+Type=Fixed
+
+[scalable/apps]
+Size=128
+MinSize=64
+MaxSize=256
+Type=Scalable
+`
+
+// writeFile writes the specified content to the specified path, creating
+// the parent directories if needed.
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModeDir|0o700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+// setIconDirs sets up temporary base directories and returns the locations
+// of the user and system icons directories.
+func setIconDirs(t *testing.T) (string, string, string) {
+	t.Cleanup(xdg.Reload)
+	root := t.TempDir()
+	t.Setenv("HOME", filepath.Join(root, "home"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(root, "home", ".local", "share"))
+	t.Setenv("XDG_DATA_DIRS", filepath.Join(root, "usr", "share"))
+	xdg.Reload()
+
+	return filepath.Join(root, "home", ".icons"),
+		filepath.Join(root, "home", ".local", "share", "icons"),
+		filepath.Join(root, "usr", "share", "icons")
+}
+
+func TestDirs(t *testing.T) {
+	homeDir, userDir, systemDir := setIconDirs(t)
+
+	dirs := icontheme.Dirs()
+	require.Equal(t, []string{homeDir, userDir, systemDir, "/usr/share/pixmaps"}, dirs)
+}
+
+func TestLoadTheme(t *testing.T) {
+	homeDir, _, systemDir := setIconDirs(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(homeDir, "Test"), os.ModeDir|0o700))
+	writeFile(t, filepath.Join(systemDir, "Test", "index.theme"), testThemeIndex)
+	writeFile(t, filepath.Join(systemDir, "Invalid", "index.theme"), "[Icon Theme]\nDirectories=a\n\n[a]\nSize=abc\n")
+	writeFile(t, filepath.Join(systemDir, "Empty", "index.theme"), "[Other]\n")
+
+	t.Setenv("LC_ALL", "fr_FR.UTF-8")
+	theme, err := icontheme.LoadTheme("Test")
+	require.NoError(t, err)
+	require.Equal(t, "Test", theme.ID)
+	require.Equal(t, filepath.Join(systemDir, "Test", "index.theme"), theme.Path)
+	require.Equal(t, "Essai", theme.Name)
+	require.Equal(t, "Test theme", theme.Comment)
+	require.Equal(t, []string{"Parent"}, theme.Inherits)
+	require.Equal(t, "folder", theme.Example)
+	require.False(t, theme.Hidden)
+	require.Equal(t, []*icontheme.Directory{
+		{Path: "16x16/apps", Size: 16, Scale: 1, Context: "Applications", Type: icontheme.TypeFixed, MinSize: 16, MaxSize: 16, Threshold: 2},
+		{Path: "48x48/apps", Size: 48, Scale: 1, Type: icontheme.TypeFixed, MinSize: 48, MaxSize: 48, Threshold: 2},
+		{Path: "scalable/apps", Size: 128, Scale: 1, Type: icontheme.TypeScalable, MinSize: 64, MaxSize: 256, Threshold: 2},
+		{Path: "24x24@2/apps", Size: 24, Scale: 2, Type: icontheme.TypeFixed, MinSize: 24, MaxSize: 24, Threshold: 2},
+	}, theme.Directories)
+
+	_, err = icontheme.LoadTheme("Missing")
+	require.True(t, errors.Is(err, icontheme.ErrNotFound))
+
+	for _, id := range []string{"Invalid", "Empty", "", "..", "a/b"} {
+		_, err = icontheme.LoadTheme(id)
+		require.Error(t, err, id)
+		require.False(t, errors.Is(err, icontheme.ErrNotFound), id)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	homeDir, userDir, systemDir := setIconDirs(t)
+
+	// Test theme.
+	writeFile(t, filepath.Join(systemDir, "Test", "index.theme"), testThemeIndex)
+	writeFile(t, filepath.Join(systemDir, "Test", "16x16", "apps", "app.png"), "")
+	writeFile(t, filepath.Join(homeDir, "Test", "48x48", "apps", "app.svg"), "")
+	writeFile(t, filepath.Join(systemDir, "Test", "48x48", "apps", "app.png"), "")
+	writeFile(t, filepath.Join(systemDir, "Test", "24x24@2", "apps", "app.png"), "")
+	writeFile(t, filepath.Join(systemDir, "Test", "scalable", "apps", "app.svg"), "")
+	writeFile(t, filepath.Join(systemDir, "Test", "16x16", "apps", "small.xpm"), "")
+
+	// Parent theme, which inherits from a missing theme, from a malformed
+	// theme and from the test theme, creating a cycle.
+	writeFile(t, filepath.Join(userDir, "Parent", "index.theme"), `[Icon Theme]
+Name=Parent
+Inherits=Missing,Broken,Test
+Directories=32x32/apps
+
+[32x32/apps]
+Size=32
+`)
+	writeFile(t, filepath.Join(userDir, "Parent", "32x32", "apps", "parent.png"), "")
+	writeFile(t, filepath.Join(userDir, "Broken", "index.theme"), "[Icon Theme]\nDirectories=a\n\n[a]\nSize=abc\n")
+	writeFile(t, filepath.Join(userDir, "Broken", "a", "app.png"), "")
+	writeFile(t, filepath.Join(userDir, "Child", "index.theme"), "[Icon Theme]\nName=Child\nInherits=Broken\n")
+
+	// Fallback theme.
+	writeFile(t, filepath.Join(systemDir, "hicolor", "index.theme"), `[Icon Theme]
+Name=Hicolor
+Directories=48x48/apps
+
+[48x48/apps]
+Size=48
+Type=Fixed
+`)
+	writeFile(t, filepath.Join(systemDir, "hicolor", "48x48", "apps", "fallback.png"), "")
+	writeFile(t, filepath.Join(systemDir, "unthemed.png"), "")
+
+	tests := []struct {
+		theme, name string
+		size, scale int
+		expected    string
+	}{
+		{"Test", "app", 16, 1, filepath.Join(systemDir, "Test", "16x16", "apps", "app.png")},
+		{"Test", "app", 48, 1, filepath.Join(homeDir, "Test", "48x48", "apps", "app.svg")},
+		{"Test", "app", 24, 2, filepath.Join(systemDir, "Test", "24x24@2", "apps", "app.png")},
+		{"Test", "app", 128, 1, filepath.Join(systemDir, "Test", "scalable", "apps", "app.svg")},
+		{"Test", "app", 40, 1, filepath.Join(homeDir, "Test", "48x48", "apps", "app.svg")},
+		{"Test", "app", 1024, 1, filepath.Join(systemDir, "Test", "scalable", "apps", "app.svg")},
+		{"Test", "small", 256, 1, filepath.Join(systemDir, "Test", "16x16", "apps", "small.xpm")},
+		{"Test", "parent", 48, 1, filepath.Join(userDir, "Parent", "32x32", "apps", "parent.png")},
+		{"Test", "fallback", 16, 1, filepath.Join(systemDir, "hicolor", "48x48", "apps", "fallback.png")},
+		{"Parent", "app", 16, 1, filepath.Join(systemDir, "Test", "16x16", "apps", "app.png")},
+		{"Missing", "fallback", 48, 1, filepath.Join(systemDir, "hicolor", "48x48", "apps", "fallback.png")},
+		{"Broken", "fallback", 48, 1, filepath.Join(systemDir, "hicolor", "48x48", "apps", "fallback.png")},
+		{"Child", "fallback", 48, 1, filepath.Join(systemDir, "hicolor", "48x48", "apps", "fallback.png")},
+		{"", "fallback", 48, 1, filepath.Join(systemDir, "hicolor", "48x48", "apps", "fallback.png")},
+		{"Test", "unthemed", 48, 1, filepath.Join(systemDir, "unthemed.png")},
+		{"Test", filepath.Join(systemDir, "unthemed.png"), 48, 1, filepath.Join(systemDir, "unthemed.png")},
+	}
+	for _, test := range tests {
+		path, err := icontheme.Lookup(test.theme, test.name, test.size, test.scale)
+		require.NoError(t, err, test.name)
+		require.Equal(t, test.expected, path, test.name)
+	}
+
+	_, err := icontheme.Lookup("Test", "missing", 48, 1)
+	require.True(t, errors.Is(err, icontheme.ErrNotFound))
+	for _, name := range []string{"", "apps/app", filepath.Join(systemDir, "missing.png")} {
+		_, err = icontheme.Lookup("Test", name, 48, 1)
+		require.Error(t, err, name)
+	}
+
+	// Theme lookup.
+	theme, err := icontheme.LoadTheme("Parent")
+	require.NoError(t, err)
+
+	path, err := theme.Lookup("app", 48, 1)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(homeDir, "Test", "48x48", "apps", "app.svg"), path)
+
+	path, err = theme.Lookup("fallback", 48, 1)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(systemDir, "hicolor", "48x48", "apps", "fallback.png"), path)
+
+	_, err = theme.Lookup("unthemed", 48, 1)
+	require.True(t, errors.Is(err, icontheme.ErrNotFound))
+}
+
+func TestInstallIcon(t *testing.T) {
+	_, userDir, systemDir := setIconDirs(t)
+	writeFile(t, filepath.Join(systemDir, "hicolor", "index.theme"), `[Icon Theme]
+Name=Hicolor
+Directories=16x16/apps,48x48/apps,scalable/apps
+
+[16x16/apps]
+Size=16
+Type=Threshold
+
+[48x48/apps]
+Size=48
+Type=Threshold
+
+[scalable/apps]
+Size=128
+MinSize=8
+MaxSize=512
+Type=Scalable
+`)
+	writeFile(t, filepath.Join(systemDir, "hicolor", "48x48", "apps", "xdg-test.png"), "system")
+
+	srcDir := t.TempDir()
+	pngIcon, svgIcon := filepath.Join(srcDir, "xdg-test.png"), filepath.Join(srcDir, "icon.svg")
+	writeFile(t, pngIcon, "png")
+	writeFile(t, svgIcon, "svg")
+
+	require.NoError(t, icontheme.InstallIcon(pngIcon, "", 16, 48))
+	require.NoError(t, icontheme.InstallIcon(svgIcon, "xdg-test", icontheme.Scalable))
+
+	themeDir := filepath.Join(userDir, "hicolor")
+	data, err := os.ReadFile(filepath.Join(themeDir, "48x48", "apps", "xdg-test.png"))
+	require.NoError(t, err)
+	require.Equal(t, "png", string(data))
+	require.FileExists(t, filepath.Join(themeDir, "16x16", "apps", "xdg-test.png"))
+	require.FileExists(t, filepath.Join(themeDir, "scalable", "apps", "xdg-test.svg"))
+
+	tests := map[int]string{
+		16:  filepath.Join(themeDir, "16x16", "apps", "xdg-test.png"),
+		48:  filepath.Join(themeDir, "48x48", "apps", "xdg-test.png"),
+		256: filepath.Join(themeDir, "scalable", "apps", "xdg-test.svg"),
+	}
+	for size, expected := range tests {
+		path, err := icontheme.Lookup("", "xdg-test", size, 1)
+		require.NoError(t, err)
+		require.Equal(t, expected, path)
+	}
+
+	// Replace icon with a different extension.
+	require.NoError(t, icontheme.InstallIcon(svgIcon, "xdg-test", 16))
+	require.NoFileExists(t, filepath.Join(themeDir, "16x16", "apps", "xdg-test.png"))
+	require.FileExists(t, filepath.Join(themeDir, "16x16", "apps", "xdg-test.svg"))
+
+	// Uninstall icons.
+	require.NoError(t, icontheme.UninstallIcon("xdg-test", 48))
+	require.NoFileExists(t, filepath.Join(themeDir, "48x48", "apps", "xdg-test.png"))
+
+	path, err := icontheme.Lookup("", "xdg-test", 48, 1)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(systemDir, "hicolor", "48x48", "apps", "xdg-test.png"), path)
+
+	require.NoError(t, icontheme.UninstallIcon("xdg-test"))
+	require.NoFileExists(t, filepath.Join(themeDir, "16x16", "apps", "xdg-test.svg"))
+	require.NoFileExists(t, filepath.Join(themeDir, "scalable", "apps", "xdg-test.svg"))
+
+	err = icontheme.UninstallIcon("xdg-test")
+	require.True(t, errors.Is(err, icontheme.ErrNotFound))
+
+	// Invalid arguments.
+	writeFile(t, filepath.Join(srcDir, "icon.gif"), "gif")
+	invalid := []struct {
+		src, name string
+		sizes     []int
+	}{
+		{filepath.Join(srcDir, "icon.gif"), "", []int{48}},
+		{pngIcon, "", nil},
+		{pngIcon, "", []int{-1}},
+		{pngIcon, "", []int{icontheme.Scalable}},
+		{pngIcon, "a/b", []int{48}},
+		{filepath.Join(srcDir, "missing.png"), "", []int{48}},
+	}
+	for _, test := range invalid {
+		require.Error(t, icontheme.InstallIcon(test.src, test.name, test.sizes...), test.src)
+	}
+	require.Error(t, icontheme.UninstallIcon(".."))
+	require.Error(t, icontheme.UninstallIcon("xdg-test", -1))
+}
@@ -0,0 +1,165 @@
+package icontheme
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/adrg/xdg/internal/fileutil"
+)
+
+const (
+	// Scalable can be used as the size of icons installed using InstallIcon
+	// in order to install scalable icons, which must be SVG files.
+	Scalable = 0
+
+	// appsContext is the name of the theme subdirectories which contain
+	// application icons.
+	appsContext = "apps"
+)
+
+// InstallIcon installs the icon file at the specified location as an
+// application icon of the hicolor theme, located in xdg.DataHome, similar
+// to the xdg-icon-resource utility. The icon is copied to the
+// $XDG_DATA_HOME/icons/hicolor/<size>x<size>/apps directory for each of the
+// specified sizes, or to the $XDG_DATA_HOME/icons/hicolor/scalable/apps
+// directory for the Scalable size. The file must be a PNG, SVG or XPM image.
+//
+// The name of the icon, which is the value of the Icon key of desktop
+// entries, can be empty, in which case it is derived from the name of the
+// file, without the extension. Installed icons of the same name and size
+// having a different extension are replaced.
+//
+// The sizes the icon is installed for should be among the ones listed in
+// the index.theme file of the hicolor theme, which is usually provided by
+// the system, as icons stored in other directories are not found by
+// Lookup. The content of the file is not checked against the sizes.
+func InstallIcon(src, name string, sizes ...int) error {
+	ext := strings.ToLower(filepath.Ext(src))
+	if !slices.Contains(extensions, ext) {
+		return fmt.Errorf("unsupported icon file `%s`", src)
+	}
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(src), filepath.Ext(src))
+	}
+	if err := validateIconName(name); err != nil {
+		return err
+	}
+	if len(sizes) == 0 {
+		return errors.New("no icon sizes specified")
+	}
+
+	dirs := make([]string, 0, len(sizes))
+	for _, size := range sizes {
+		if size < 0 || size == Scalable && ext != ".svg" {
+			return fmt.Errorf("invalid size %d for icon file `%s`", size, src)
+		}
+		dirs = append(dirs, sizeDir(size))
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	themeDir := filepath.Join(xdg.DataHome, "icons", fallbackTheme)
+	for _, dir := range dirs {
+		dir = filepath.Join(themeDir, dir)
+		if err := os.MkdirAll(dir, os.ModeDir|0o755); err != nil {
+			return err
+		}
+		if err := fileutil.WriteAtomic(filepath.Join(dir, name+ext), data, 0o644); err != nil {
+			return err
+		}
+
+		// Remove icons of the same name having a different extension, which
+		// could take precedence over the installed icon.
+		for _, e := range extensions {
+			if e == ext {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, name+e)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+
+	return touch(themeDir)
+}
+
+// UninstallIcon removes the application icon with the specified name from
+// the hicolor theme located in xdg.DataHome, for each of the specified sizes.
+// If no sizes are specified, the icon is removed from all the application
+// icon directories of the theme. Returns ErrNotFound if no icons are removed.
+func UninstallIcon(name string, sizes ...int) error {
+	if err := validateIconName(name); err != nil {
+		return err
+	}
+
+	themeDir := filepath.Join(xdg.DataHome, "icons", fallbackTheme)
+
+	var dirs []string
+	if len(sizes) == 0 {
+		var err error
+		if dirs, err = filepath.Glob(filepath.Join(themeDir, "*", appsContext)); err != nil {
+			return err
+		}
+	}
+	for _, size := range sizes {
+		if size < 0 {
+			return fmt.Errorf("invalid icon size %d", size)
+		}
+		dirs = append(dirs, filepath.Join(themeDir, sizeDir(size)))
+	}
+
+	removed := false
+	for _, dir := range dirs {
+		for _, ext := range extensions {
+			err := os.Remove(filepath.Join(dir, name+ext))
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					continue
+				}
+				return err
+			}
+			removed = true
+		}
+	}
+	if !removed {
+		return fmt.Errorf("%w: could not locate `%s` in %s", ErrNotFound, name, themeDir)
+	}
+
+	return touch(themeDir)
+}
+
+// sizeDir returns the path of the application icon directory of the
+// specified size, relative to the directory of the theme.
+func sizeDir(size int) string {
+	if size == Scalable {
+		return filepath.Join("scalable", appsContext)
+	}
+
+	dim := strconv.Itoa(size)
+	return filepath.Join(dim+"x"+dim, appsContext)
+}
+
+func validateIconName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid icon name `%s`", name)
+	}
+
+	return nil
+}
+
+// touch updates the modification time of the specified theme directory, in
+// order to signal icon caches that the theme changed.
+func touch(dir string) error {
+	now := time.Now()
+	return os.Chtimes(dir, now, now)
+}
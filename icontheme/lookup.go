@@ -0,0 +1,200 @@
+package icontheme
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/adrg/xdg/internal/pathutil"
+)
+
+// extensions contains the supported icon file extensions, in order of
+// preference.
+var extensions = []string{".png", ".svg", ".xpm"}
+
+// Dirs returns the base directories searched for icon themes and icons,
+// in order of precedence: the .icons directory inside xdg.Home, the icons
+// directories inside xdg.DataHome and xdg.DataDirs and, on Unix-like
+// systems, /usr/share/pixmaps.
+func Dirs() []string {
+	dirs := []string{
+		filepath.Join(xdg.Home, ".icons"),
+		filepath.Join(xdg.DataHome, "icons"),
+	}
+	for _, dir := range xdg.DataDirs {
+		dirs = append(dirs, filepath.Join(dir, "icons"))
+	}
+
+	return pathutil.Unique(append(dirs, pixmapsDirs...))
+}
+
+// Lookup returns the location of the icon with the specified name, using
+// the icon theme with the specified ID, as described by the Icon Theme
+// Specification. The icon is searched in the specified theme and the themes
+// it inherits from, followed by the hicolor theme. Themes which cannot be
+// loaded are skipped. If the icon is not part of any theme, it is searched
+// directly inside the base directories returned by Dirs. Returns
+// ErrNotFound if the icon cannot be located.
+//
+// The icon which best matches the specified size and scale is returned.
+// If no icon matches them exactly, the icon with the closest size is chosen.
+// An empty theme ID can be provided in order to use the hicolor theme.
+// Absolute icon paths, allowed by the Icon key of desktop entries, are
+// returned unchanged if the file exists.
+func Lookup(theme, name string, size, scale int) (string, error) {
+	if filepath.IsAbs(name) {
+		if _, err := os.Stat(name); err != nil {
+			return "", err
+		}
+		return name, nil
+	}
+	if name == "" || strings.ContainsAny(name, "/"+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid icon name `%s`", name)
+	}
+
+	seen := map[string]bool{}
+	for _, id := range []string{theme, fallbackTheme} {
+		if id == "" {
+			continue
+		}
+		if path, ok := findIcon(id, name, size, scale, seen); ok {
+			return path, nil
+		}
+	}
+
+	// Search the base directories.
+	dirs := Dirs()
+	if path, ok := findFile(dirs, name); ok {
+		return path, nil
+	}
+
+	return "", fmt.Errorf("%w: could not locate `%s` in any of the following paths: %s",
+		ErrNotFound, name, joinPaths(dirs))
+}
+
+// Lookup returns the location of the icon with the specified name, searched
+// in the theme and in the themes it inherits from, recursively, followed by
+// the hicolor theme. Unlike the Lookup function, the base directories are
+// not searched directly. Returns ErrNotFound if the icon cannot be located.
+// See Lookup for more details.
+func (t *Theme) Lookup(name string, size, scale int) (string, error) {
+	if name == "" || strings.ContainsAny(name, "/"+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid icon name `%s`", name)
+	}
+
+	seen := map[string]bool{t.ID: true}
+	if path, ok := t.lookupIcon(name, size, scale); ok {
+		return path, nil
+	}
+	for _, parent := range append(slices.Clone(t.parents()), fallbackTheme) {
+		if path, ok := findIcon(parent, name, size, scale, seen); ok {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: could not locate `%s` in theme `%s`", ErrNotFound, name, t.ID)
+}
+
+// findIcon searches the icon with the specified name in the theme with the
+// specified ID and in the themes it inherits from. Themes which were already
+// searched, tracked using the provided map, are skipped, so that inheritance
+// cycles are handled. Themes which cannot be loaded (e.g. missing themes or
+// themes with malformed index files) are treated as not containing the icon,
+// so that the search continues with the remaining themes.
+func findIcon(id, name string, size, scale int, seen map[string]bool) (string, bool) {
+	if seen[id] {
+		return "", false
+	}
+	seen[id] = true
+
+	theme, err := LoadTheme(id)
+	if err != nil {
+		return "", false
+	}
+	if path, ok := theme.lookupIcon(name, size, scale); ok {
+		return path, true
+	}
+
+	for _, parent := range theme.parents() {
+		if path, ok := findIcon(parent, name, size, scale, seen); ok {
+			return path, true
+		}
+	}
+
+	return "", false
+}
+
+// parents returns the IDs of the themes the theme inherits from.
+func (t *Theme) parents() []string {
+	if len(t.Inherits) == 0 && t.ID != fallbackTheme {
+		return []string{fallbackTheme}
+	}
+
+	return t.Inherits
+}
+
+// lookupIcon searches the icon with the specified name in the directories
+// of the theme. Directories matching the specified size and scale are
+// searched first. Otherwise, the icon found in the directory with the
+// closest size is returned.
+func (t *Theme) lookupIcon(name string, size, scale int) (string, bool) {
+	for _, dir := range t.Directories {
+		if !dir.matchesSize(size, scale) {
+			continue
+		}
+		if path, ok := findFile(t.dirPaths(dir), name); ok {
+			return path, true
+		}
+	}
+
+	var (
+		closest     string
+		minDistance = math.MaxInt
+	)
+	for _, dir := range t.Directories {
+		distance := dir.sizeDistance(size, scale)
+		if distance >= minDistance {
+			continue
+		}
+		if path, ok := findFile(t.dirPaths(dir), name); ok {
+			closest, minDistance = path, distance
+		}
+	}
+
+	return closest, closest != ""
+}
+
+// dirPaths returns the locations of the specified theme directory inside
+// each base directory containing the theme.
+func (t *Theme) dirPaths(dir *Directory) []string {
+	paths := make([]string, 0, len(t.baseDirs))
+	for _, baseDir := range t.baseDirs {
+		paths = append(paths, filepath.Join(baseDir, t.ID, filepath.FromSlash(dir.Path)))
+	}
+
+	return paths
+}
+
+// findFile returns the location of the first icon file with the specified
+// name and one of the supported extensions, found in the specified
+// directories.
+func findFile(dirs []string, name string) (string, bool) {
+	for _, dir := range dirs {
+		for _, ext := range extensions {
+			path := filepath.Join(dir, name+ext)
+			if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
+				return path, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func joinPaths(paths []string) string {
+	return strings.Join(paths, string(os.PathListSeparator))
+}
@@ -0,0 +1,305 @@
+package icontheme
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/adrg/xdg/internal/keyfile"
+	"github.com/adrg/xdg/internal/locale"
+)
+
+const (
+	// indexFile is the name of the file describing an icon theme.
+	indexFile = "index.theme"
+
+	// themeGroup is the name of the main group of index.theme files.
+	themeGroup = "Icon Theme"
+
+	// fallbackTheme is the theme which all icon themes implicitly inherit.
+	fallbackTheme = "hicolor"
+)
+
+// ErrNotFound is returned when an icon theme or an icon cannot be located.
+var ErrNotFound = errors.New("icon not found")
+
+// DirType specifies how the icons of an icon theme directory can be scaled.
+type DirType string
+
+// Icon theme directory types.
+const (
+	// TypeFixed indicates that the icons of the directory cannot be scaled.
+	TypeFixed DirType = "Fixed"
+
+	// TypeScalable indicates that the icons of the directory can be scaled
+	// to any size between MinSize and MaxSize.
+	TypeScalable DirType = "Scalable"
+
+	// TypeThreshold indicates that the icons of the directory can be used
+	// for sizes which differ from Size by at most Threshold.
+	TypeThreshold DirType = "Threshold"
+)
+
+// Directory represents a subdirectory of an icon theme, which contains
+// icons of a specific size.
+type Directory struct {
+	// Path contains the path of the directory, relative to the directory
+	// of the theme (e.g. 48x48/apps).
+	Path string
+
+	// Size contains the nominal size of the icons of the directory.
+	Size int
+
+	// Scale contains the target scale of the icons of the directory.
+	Scale int
+
+	// Context contains the context of the icons of the directory
+	// (e.g. Applications or MimeTypes).
+	Context string
+
+	// Type specifies how the icons of the directory can be scaled.
+	Type DirType
+
+	// MinSize and MaxSize contain the range of sizes the icons of
+	// Scalable directories can be scaled to.
+	MinSize int
+	MaxSize int
+
+	// Threshold contains the maximum difference from Size for which the
+	// icons of Threshold directories can be used.
+	Threshold int
+}
+
+// matchesSize returns true if the icons of the directory can be used for
+// the specified size and scale.
+func (d *Directory) matchesSize(size, scale int) bool {
+	if d.Scale != scale {
+		return false
+	}
+
+	switch d.Type {
+	case TypeFixed:
+		return d.Size == size
+	case TypeScalable:
+		return d.MinSize <= size && size <= d.MaxSize
+	default:
+		return d.Size-d.Threshold <= size && size <= d.Size+d.Threshold
+	}
+}
+
+// sizeDistance returns the difference between the size of the icons of the
+// directory and the specified size, taking the scales into account.
+func (d *Directory) sizeDistance(size, scale int) int {
+	minSize, maxSize := d.MinSize, d.MaxSize
+	switch d.Type {
+	case TypeFixed:
+		minSize, maxSize = d.Size, d.Size
+	case TypeThreshold:
+		minSize, maxSize = d.Size-d.Threshold, d.Size+d.Threshold
+	}
+
+	switch scaled := size * scale; {
+	case scaled < minSize*d.Scale:
+		return minSize*d.Scale - scaled
+	case scaled > maxSize*d.Scale:
+		return scaled - maxSize*d.Scale
+	default:
+		return 0
+	}
+}
+
+// Theme represents an icon theme, described by the index.theme file of
+// the theme directory.
+type Theme struct {
+	// ID contains the name of the theme directory (e.g. hicolor).
+	ID string
+
+	// Path contains the location of the index.theme file of the theme.
+	Path string
+
+	// Name contains the localized display name of the theme.
+	Name string
+
+	// Comment contains the localized description of the theme.
+	Comment string
+
+	// Inherits contains the IDs of the themes the theme inherits from,
+	// in order of precedence. All themes, except for hicolor, implicitly
+	// inherit from hicolor if no themes are specified.
+	Inherits []string
+
+	// Directories contains the subdirectories of the theme, including the
+	// ones listed by the ScaledDirectories key.
+	Directories []*Directory
+
+	// Hidden specifies whether the theme should be shown to the user when
+	// listing the available themes.
+	Hidden bool
+
+	// Example contains the name of an icon used as an example of the theme.
+	Example string
+
+	// baseDirs contains the base directories which contain a directory of
+	// the theme.
+	baseDirs []string
+}
+
+// LoadTheme loads the icon theme with the specified ID, searching the base
+// directories returned by Dirs. The index.theme file of the theme is read
+// from the first base directory which contains it, while the icons of the
+// theme are searched in all the base directories containing a directory of
+// the theme. Returns ErrNotFound if the theme cannot be located.
+func LoadTheme(id string) (*Theme, error) {
+	if id == "" || id == "." || id == ".." || filepath.Base(id) != id {
+		return nil, fmt.Errorf("invalid icon theme `%s`", id)
+	}
+
+	var (
+		theme    *Theme
+		baseDirs []string
+		dirs     = Dirs()
+	)
+	for _, dir := range dirs {
+		if fi, err := os.Stat(filepath.Join(dir, id)); err != nil || !fi.IsDir() {
+			continue
+		}
+		baseDirs = append(baseDirs, dir)
+
+		name := filepath.Join(dir, id, indexFile)
+		if theme != nil {
+			continue
+		}
+		if _, err := os.Stat(name); err != nil {
+			continue
+		}
+
+		var err error
+		if theme, err = parseTheme(name, locale.Messages()); err != nil {
+			return nil, err
+		}
+		theme.ID = id
+	}
+	if theme == nil {
+		return nil, fmt.Errorf("%w: could not locate theme `%s` in any of the following paths: %s",
+			ErrNotFound, id, joinPaths(dirs))
+	}
+	theme.baseDirs = baseDirs
+
+	return theme, nil
+}
+
+// parseTheme parses the index.theme file at the specified location.
+func parseTheme(name string, loc locale.Locale) (*Theme, error) {
+	kf, err := keyfile.ParseFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	group := kf.Group(themeGroup)
+	if group == nil {
+		return nil, fmt.Errorf("%s: missing [%s] group", name, themeGroup)
+	}
+
+	theme := &Theme{Path: name}
+	if value, ok := group.LocalizedValue("Name", loc); ok {
+		theme.Name = keyfile.String(value)
+	}
+	if value, ok := group.LocalizedValue("Comment", loc); ok {
+		theme.Comment = keyfile.String(value)
+	}
+	if value, ok := group.Value("Inherits"); ok {
+		theme.Inherits = splitList(value)
+	}
+	if value, ok := group.Value("Hidden"); ok {
+		theme.Hidden, _ = keyfile.Bool(value)
+	}
+	if value, ok := group.Value("Example"); ok {
+		theme.Example = keyfile.String(value)
+	}
+
+	// Parse directories. Directories without a corresponding group are
+	// ignored.
+	var paths []string
+	for _, key := range []string{"Directories", "ScaledDirectories"} {
+		if value, ok := group.Value(key); ok {
+			paths = append(paths, splitList(value)...)
+		}
+	}
+	for _, path := range paths {
+		group := kf.Group(path)
+		if group == nil {
+			continue
+		}
+
+		dir, err := parseDirectory(group)
+		if err != nil {
+			return nil, fmt.Errorf("%s: [%s]: %w", name, path, err)
+		}
+		theme.Directories = append(theme.Directories, dir)
+	}
+
+	return theme, nil
+}
+
+// parseDirectory parses the group describing a directory of an icon theme.
+func parseDirectory(group *keyfile.Group) (*Directory, error) {
+	dir := &Directory{Path: group.Name, Scale: 1, Type: TypeThreshold, Threshold: 2}
+
+	value, ok := group.Value("Size")
+	if !ok {
+		return nil, errors.New("missing Size key")
+	}
+
+	var err error
+	if dir.Size, err = strconv.Atoi(value); err != nil {
+		return nil, fmt.Errorf("invalid Size value: %w", err)
+	}
+	dir.MinSize, dir.MaxSize = dir.Size, dir.Size
+
+	ints := []struct {
+		key   string
+		value *int
+	}{
+		{"Scale", &dir.Scale},
+		{"MinSize", &dir.MinSize},
+		{"MaxSize", &dir.MaxSize},
+		{"Threshold", &dir.Threshold},
+	}
+	for _, i := range ints {
+		value, ok := group.Value(i.key)
+		if !ok {
+			continue
+		}
+		if *i.value, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", i.key, err)
+		}
+	}
+
+	if value, ok := group.Value("Type"); ok {
+		switch dirType := DirType(value); dirType {
+		case TypeFixed, TypeScalable, TypeThreshold:
+			dir.Type = dirType
+		default:
+			return nil, fmt.Errorf("invalid Type value `%s`", value)
+		}
+	}
+	dir.Context, _ = group.Value("Context")
+
+	return dir, nil
+}
+
+// splitList splits the specified comma-separated list, as used by the
+// Inherits and Directories keys. Empty items are ignored.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(keyfile.String(value), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,62 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteAtomic writes the provided data to the file with the specified name.
+// The data is written to a temporary file in the same directory, which then
+// replaces the target file. Readers of the file observe either its previous
+// or its new contents, never a partially written file.
+func WriteAtomic(name string, data []byte, perm os.FileMode) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Chmod(perm); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), name)
+}
+
+// Lock acquires an exclusive lock on the file with the specified name,
+// creating it if it does not exist. The call blocks until the lock is
+// acquired. The returned function must be called in order to release
+// the lock. The lock is advisory and is intended to be used as a mutex
+// between cooperating processes and goroutines.
+func Lock(name string) (func() error, error) {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return func() error {
+		err := unlockFile(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,86 @@
+package fileutil_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adrg/xdg/internal/fileutil"
+)
+
+func TestWriteAtomic(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "file")
+
+	// Test new file.
+	require.NoError(t, fileutil.WriteAtomic(name, []byte("first"), 0o600))
+
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	require.Equal(t, "first", string(data))
+
+	// Test existing file.
+	require.NoError(t, fileutil.WriteAtomic(name, []byte("second"), 0o600))
+
+	data, err = os.ReadFile(name)
+	require.NoError(t, err)
+	require.Equal(t, "second", string(data))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// Test invalid path.
+	require.Error(t, fileutil.WriteAtomic(filepath.Join(dir, "missing", "file"), nil, 0o600))
+}
+
+func TestLock(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "file.lock")
+
+	// The lock holders are tracked atomically, as the race detector is not
+	// aware of the synchronization provided by file locks.
+	var (
+		wg      sync.WaitGroup
+		holders atomic.Int32
+		counter atomic.Int32
+		errs    = make(chan error, 16)
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			unlock, err := fileutil.Lock(name)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			if n := holders.Add(1); n != 1 {
+				errs <- fmt.Errorf("lock held by %d holders", n)
+			}
+			time.Sleep(time.Millisecond)
+			holders.Add(-1)
+			counter.Add(1)
+
+			errs <- unlock()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(8), counter.Load())
+
+	// Test invalid path.
+	_, err := fileutil.Lock(filepath.Join(t.TempDir(), "missing", "file.lock"))
+	require.Error(t, err)
+}
@@ -0,0 +1,37 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package fileutil
+
+import (
+	"os"
+	"sync"
+)
+
+// On platforms without advisory file locking, locks are only effective
+// between goroutines of the same process.
+var (
+	locksMu sync.Mutex
+	locks   = map[string]*sync.Mutex{}
+)
+
+func lockFile(f *os.File) error {
+	locksMu.Lock()
+	mu, ok := locks[f.Name()]
+	if !ok {
+		mu = &sync.Mutex{}
+		locks[f.Name()] = mu
+	}
+	locksMu.Unlock()
+
+	mu.Lock()
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	locksMu.Lock()
+	mu := locks[f.Name()]
+	locksMu.Unlock()
+
+	mu.Unlock()
+	return nil
+}
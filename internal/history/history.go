@@ -0,0 +1,163 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"iter"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/adrg/xdg/internal/fileutil"
+)
+
+// lockSuffix is appended to the name of the history file in order to obtain
+// the name of the file used to synchronize writers.
+const lockSuffix = ".lock"
+
+// Options defines the settings of a history file.
+type Options struct {
+	// MaxEntries defines the maximum number of entries retained in the
+	// history file. When the limit is exceeded, the oldest entries are
+	// discarded. A value of zero retains all entries.
+	MaxEntries int
+
+	// Dedupe specifies whether adding an entry which is already present in
+	// the history file moves it to the end of the history, instead of
+	// storing it again.
+	Dedupe bool
+}
+
+// History provides access to a history file, which stores a list of entries
+// in chronological order. Entries can be safely appended to the same file by
+// multiple processes concurrently.
+type History struct {
+	name string
+	opts Options
+}
+
+// New returns a history backed by the file with the specified name.
+// The file is created when the first entry is appended to it.
+// The parent directories of the file must exist.
+func New(name string, opts Options) *History {
+	return &History{name: name, opts: opts}
+}
+
+// Name returns the path of the history file.
+func (h *History) Name() string {
+	return h.name
+}
+
+// Append adds the specified entries to the end of the history file.
+func (h *History) Append(entries ...string) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	return h.update(func(current []string) []string {
+		for _, entry := range entries {
+			if h.opts.Dedupe {
+				current = slices.DeleteFunc(current, func(e string) bool {
+					return e == entry
+				})
+			}
+			current = append(current, entry)
+		}
+
+		return current
+	})
+}
+
+// Remove removes all occurrences of the specified entry from the history file.
+func (h *History) Remove(entry string) error {
+	return h.update(func(current []string) []string {
+		return slices.DeleteFunc(current, func(e string) bool {
+			return e == entry
+		})
+	})
+}
+
+// Clear removes all the entries from the history file.
+func (h *History) Clear() error {
+	return h.update(func([]string) []string {
+		return nil
+	})
+}
+
+// Entries returns the entries of the history file, from oldest to newest.
+// If the history file does not exist, no entries and no error are returned.
+func (h *History) Entries() ([]string, error) {
+	data, err := os.ReadFile(h.name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entries []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		entries = append(entries, unescape(scanner.Text()))
+	}
+
+	return entries, scanner.Err()
+}
+
+// Backward returns an iterator over the entries of the history file, from
+// newest to oldest. The iterator yields the index of each entry, in
+// chronological order, along with the entry itself. The iterator operates
+// on a snapshot of the history file, taken when Backward is called.
+func (h *History) Backward() (iter.Seq2[int, string], error) {
+	entries, err := h.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Backward(entries), nil
+}
+
+func (h *History) update(fn func([]string) []string) (err error) {
+	unlock, err := fileutil.Lock(h.name + lockSuffix)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if uerr := unlock(); err == nil {
+			err = uerr
+		}
+	}()
+
+	entries, err := h.Entries()
+	if err != nil {
+		return err
+	}
+
+	entries = fn(entries)
+	if limit := h.opts.MaxEntries; limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		buf.WriteString(escape(entry))
+		buf.WriteByte('\n')
+	}
+
+	return fileutil.WriteAtomic(h.name, buf.Bytes(), 0o600)
+}
+
+var (
+	escaper   = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+	unescaper = strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\r`, "\r")
+)
+
+func escape(entry string) string {
+	return escaper.Replace(entry)
+}
+
+func unescape(entry string) string {
+	return unescaper.Replace(entry)
+}
@@ -0,0 +1,117 @@
+package history_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adrg/xdg/internal/history"
+)
+
+func TestHistory(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "history")
+	h := history.New(name, history.Options{MaxEntries: 3, Dedupe: true})
+	require.Equal(t, name, h.Name())
+
+	// Test non-existent history file.
+	entries, err := h.Entries()
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	// Test appending entries.
+	require.NoError(t, h.Append("ls", "cd /tmp", "ls"))
+	require.NoError(t, h.Append())
+
+	entries, err = h.Entries()
+	require.NoError(t, err)
+	require.Equal(t, []string{"cd /tmp", "ls"}, entries)
+
+	// Test entries containing special characters.
+	require.NoError(t, h.Append("echo 'a\nb'", `printf '\n'`))
+
+	entries, err = h.Entries()
+	require.NoError(t, err)
+	require.Equal(t, []string{"ls", "echo 'a\nb'", `printf '\n'`}, entries)
+
+	// Test reverse iteration.
+	seq, err := h.Backward()
+	require.NoError(t, err)
+
+	var (
+		indices  []int
+		reversed []string
+	)
+	for i, entry := range seq {
+		indices = append(indices, i)
+		reversed = append(reversed, entry)
+	}
+	require.Equal(t, []int{2, 1, 0}, indices)
+	require.Equal(t, []string{`printf '\n'`, "echo 'a\nb'", "ls"}, reversed)
+
+	// Test removing entries.
+	require.NoError(t, h.Remove("ls"))
+
+	entries, err = h.Entries()
+	require.NoError(t, err)
+	require.Equal(t, []string{"echo 'a\nb'", `printf '\n'`}, entries)
+
+	require.NoError(t, h.Clear())
+
+	entries, err = h.Entries()
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestHistoryDuplicates(t *testing.T) {
+	h := history.New(filepath.Join(t.TempDir(), "history"), history.Options{})
+
+	require.NoError(t, h.Append("ls", "ls", "pwd", "ls"))
+
+	entries, err := h.Entries()
+	require.NoError(t, err)
+	require.Equal(t, []string{"ls", "ls", "pwd", "ls"}, entries)
+}
+
+func TestHistoryConcurrentAppend(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "history")
+
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, 16)
+	)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			h := history.New(name, history.Options{Dedupe: true})
+			errs <- h.Append(fmt.Sprintf("entry %d", i))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	entries, err := history.New(name, history.Options{}).Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 16)
+}
+
+func TestHistoryInvalidPath(t *testing.T) {
+	h := history.New(filepath.Join(t.TempDir(), "missing", "history"), history.Options{})
+	require.Error(t, h.Append("ls"))
+
+	// Test unreadable history file.
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "history"), 0o700))
+
+	_, err := history.New(filepath.Join(dir, "history"), history.Options{}).Entries()
+	require.Error(t, err)
+}
@@ -0,0 +1,337 @@
+package keyfile
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/adrg/xdg/internal/locale"
+)
+
+// SyntaxError describes a malformed line of a key file.
+type SyntaxError struct {
+	// Line contains the line number, starting from 1.
+	Line int
+
+	// Msg describes the problem.
+	Msg string
+}
+
+// Error returns a textual representation of the syntax error.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// Entry represents a key-value pair of a key file group.
+type Entry struct {
+	// Key contains the name of the key, without the locale.
+	Key string
+
+	// Locale contains the locale of localized keys (e.g. fr for Name[fr]).
+	Locale string
+
+	// Value contains the raw value of the key.
+	Value string
+
+	// Line contains the line number of the entry, starting from 1.
+	// It is zero for entries which were not parsed from a file.
+	Line int
+}
+
+// line represents a line of a key file group. Lines which do not contain
+// entries are comments or blank lines, which are preserved when writing.
+type line struct {
+	entry *Entry
+	text  string
+}
+
+// Group represents a group of entries of a key file (e.g. [Desktop Entry]).
+type Group struct {
+	// Name contains the name of the group, without the square brackets.
+	Name string
+
+	// Line contains the line number of the group header, starting from 1.
+	Line int
+
+	lines []line
+}
+
+// File represents a key file, as used by desktop entries, mimeapps.list
+// files and icon theme index files. The comments and the order of the lines
+// are preserved, so a key file can be edited and written back.
+type File struct {
+	// header contains the comments and blank lines preceding the first group.
+	header []string
+	groups []*Group
+}
+
+// New returns an empty key file.
+func New() *File {
+	return &File{}
+}
+
+// ParseFile parses the key file at the specified location.
+func ParseFile(name string) (*File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	kf, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return kf, nil
+}
+
+// Parse parses the key file contained in the provided reader. Lines which are
+// not comments, blank lines, group headers or entries are reported as
+// *SyntaxError values, joined in the returned error.
+func Parse(r io.Reader) (*File, error) {
+	var (
+		kf      = &File{}
+		group   *Group
+		errs    []error
+		lineNum int
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		lineNum++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(text)
+
+		switch {
+		case trimmed == "" || trimmed[0] == '#':
+			if group == nil {
+				kf.header = append(kf.header, text)
+			} else {
+				group.lines = append(group.lines, line{text: text})
+			}
+		case trimmed[0] == '[':
+			if trimmed[len(trimmed)-1] != ']' || len(trimmed) < 3 {
+				errs = append(errs, &SyntaxError{Line: lineNum, Msg: "malformed group header"})
+				continue
+			}
+
+			group = &Group{Name: trimmed[1 : len(trimmed)-1], Line: lineNum}
+			kf.groups = append(kf.groups, group)
+		default:
+			entry, err := parseEntry(trimmed)
+			if err != nil {
+				errs = append(errs, &SyntaxError{Line: lineNum, Msg: err.Error()})
+				continue
+			}
+			if group == nil {
+				errs = append(errs, &SyntaxError{Line: lineNum, Msg: "entry outside of any group"})
+				continue
+			}
+
+			entry.Line = lineNum
+			group.lines = append(group.lines, line{entry: entry})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return kf, nil
+}
+
+func parseEntry(text string) (*Entry, error) {
+	key, value, ok := strings.Cut(text, "=")
+	if !ok {
+		return nil, errors.New("missing `=` separator")
+	}
+	if key = strings.TrimSpace(key); key == "" {
+		return nil, errors.New("empty key")
+	}
+
+	entry := &Entry{Key: key, Value: strings.TrimSpace(value)}
+	if i := strings.IndexByte(key, '['); i >= 0 {
+		if key[len(key)-1] != ']' || i == 0 {
+			return nil, fmt.Errorf("malformed localized key `%s`", key)
+		}
+		entry.Key, entry.Locale = key[:i], key[i+1:len(key)-1]
+	}
+
+	return entry, nil
+}
+
+// Groups returns the groups of the key file, in the order in which they
+// occur in the file.
+func (kf *File) Groups() []*Group {
+	return kf.groups
+}
+
+// Group returns the first group with the specified name, or nil if the key
+// file does not contain such a group.
+func (kf *File) Group(name string) *Group {
+	for _, group := range kf.groups {
+		if group.Name == name {
+			return group
+		}
+	}
+
+	return nil
+}
+
+// AddGroup returns the first group with the specified name, creating it at
+// the end of the key file if it does not exist.
+func (kf *File) AddGroup(name string) *Group {
+	if group := kf.Group(name); group != nil {
+		return group
+	}
+
+	group := &Group{Name: name}
+	kf.groups = append(kf.groups, group)
+	return group
+}
+
+// RemoveGroup removes all the groups with the specified name.
+func (kf *File) RemoveGroup(name string) {
+	groups := kf.groups[:0]
+	for _, group := range kf.groups {
+		if group.Name != name {
+			groups = append(groups, group)
+		}
+	}
+	kf.groups = groups
+}
+
+// WriteTo writes the key file to the provided writer.
+func (kf *File) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	for _, text := range kf.header {
+		buf.WriteString(text)
+		buf.WriteByte('\n')
+	}
+
+	for i, group := range kf.groups {
+		if i > 0 && buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n\n")) {
+			buf.WriteByte('\n')
+		}
+		fmt.Fprintf(&buf, "[%s]\n", group.Name)
+
+		for _, l := range group.lines {
+			if l.entry == nil {
+				buf.WriteString(l.text)
+			} else {
+				buf.WriteString(l.entry.Key)
+				if l.entry.Locale != "" {
+					fmt.Fprintf(&buf, "[%s]", l.entry.Locale)
+				}
+				buf.WriteByte('=')
+				buf.WriteString(l.entry.Value)
+			}
+			buf.WriteByte('\n')
+		}
+	}
+
+	return buf.WriteTo(w)
+}
+
+// Entries returns the entries of the group, in the order in which they
+// occur in the file.
+func (g *Group) Entries() []*Entry {
+	entries := make([]*Entry, 0, len(g.lines))
+	for _, l := range g.lines {
+		if l.entry != nil {
+			entries = append(entries, l.entry)
+		}
+	}
+
+	return entries
+}
+
+// Value returns the raw value of the specified non-localized key. If the key
+// occurs multiple times, the value of the first occurrence is returned.
+func (g *Group) Value(key string) (string, bool) {
+	return g.LocaleValue(key, "")
+}
+
+// LocaleValue returns the raw value of the specified key, for the specified
+// exact locale (e.g. fr_FR). An empty locale refers to the non-localized key.
+func (g *Group) LocaleValue(key, loc string) (string, bool) {
+	for _, l := range g.lines {
+		if l.entry != nil && l.entry.Key == key && l.entry.Locale == loc {
+			return l.entry.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// LocalizedValue returns the raw value of the specified key, matching the
+// specified locale as defined by the Desktop Entry Specification. The value
+// of the first matching localized key is returned, in the following order:
+// lang_COUNTRY@MODIFIER, lang_COUNTRY, lang@MODIFIER, lang. If none of them
+// exist, the value of the non-localized key is returned.
+func (g *Group) LocalizedValue(key string, l locale.Locale) (string, bool) {
+	for _, candidate := range l.Candidates() {
+		if value, ok := g.LocaleValue(key, candidate); ok {
+			return value, true
+		}
+	}
+
+	return g.Value(key)
+}
+
+// Set sets the raw value of the specified non-localized key. The first
+// occurrence of the key is updated, if it exists. Otherwise, the key is
+// added after the last entry of the group.
+func (g *Group) Set(key, value string) {
+	g.SetLocale(key, "", value)
+}
+
+// SetLocale sets the raw value of the specified key, for the specified exact
+// locale. See Set for more details.
+func (g *Group) SetLocale(key, loc, value string) {
+	last := -1
+	for i, l := range g.lines {
+		if l.entry == nil {
+			continue
+		}
+		if l.entry.Key == key && l.entry.Locale == loc {
+			l.entry.Value = value
+			return
+		}
+		last = i
+	}
+
+	g.lines = append(g.lines[:last+1],
+		append([]line{{entry: &Entry{Key: key, Locale: loc, Value: value}}}, g.lines[last+1:]...)...)
+}
+
+// Delete removes all the occurrences of the specified non-localized key.
+// It returns true if any occurrences were removed.
+func (g *Group) Delete(key string) bool {
+	return g.DeleteLocale(key, "")
+}
+
+// DeleteLocale removes all the occurrences of the specified key, for the
+// specified exact locale. It returns true if any occurrences were removed.
+func (g *Group) DeleteLocale(key, loc string) bool {
+	lines := g.lines[:0]
+	for _, l := range g.lines {
+		if l.entry == nil || l.entry.Key != key || l.entry.Locale != loc {
+			lines = append(lines, l)
+		}
+	}
+
+	deleted := len(lines) != len(g.lines)
+	g.lines = lines
+	return deleted
+}
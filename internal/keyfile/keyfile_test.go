@@ -0,0 +1,180 @@
+package keyfile_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adrg/xdg/internal/keyfile"
+	"github.com/adrg/xdg/internal/locale"
+)
+
+const content = `# Leading comment
+
+[Desktop Entry]
+Name=Editor
+Name[fr]=Éditeur
+Name[sr@latin]=Urednik
+ Comment = Edit files
+# Inner comment
+Exec=editor %F
+
+[Desktop Action new]
+Name=New Window
+`
+
+func TestParse(t *testing.T) {
+	kf, err := keyfile.Parse(strings.NewReader(content))
+	require.NoError(t, err)
+
+	groups := kf.Groups()
+	require.Len(t, groups, 2)
+	require.Equal(t, "Desktop Entry", groups[0].Name)
+	require.Equal(t, 3, groups[0].Line)
+	require.Equal(t, "Desktop Action new", groups[1].Name)
+	require.Nil(t, kf.Group("Missing"))
+
+	group := kf.Group("Desktop Entry")
+	require.NotNil(t, group)
+
+	entries := group.Entries()
+	require.Len(t, entries, 5)
+	require.Equal(t, keyfile.Entry{Key: "Name", Locale: "fr", Value: "Éditeur", Line: 5}, *entries[1])
+	require.Equal(t, keyfile.Entry{Key: "Comment", Value: "Edit files", Line: 7}, *entries[3])
+
+	value, ok := group.Value("Exec")
+	require.True(t, ok)
+	require.Equal(t, "editor %F", value)
+
+	_, ok = group.Value("Missing")
+	require.False(t, ok)
+
+	value, ok = group.LocaleValue("Name", "sr@latin")
+	require.True(t, ok)
+	require.Equal(t, "Urednik", value)
+}
+
+func TestLocalizedValue(t *testing.T) {
+	kf, err := keyfile.Parse(strings.NewReader(content))
+	require.NoError(t, err)
+	group := kf.Group("Desktop Entry")
+
+	tests := map[string]string{
+		"fr_FR.UTF-8":    "Éditeur",
+		"fr":             "Éditeur",
+		"sr_RS@latin":    "Urednik",
+		"sr_RS":          "Editor",
+		"de_DE.UTF-8":    "Editor",
+		"C":              "Editor",
+		"fr_CA@modifier": "Éditeur",
+	}
+	for name, expected := range tests {
+		value, ok := group.LocalizedValue("Name", locale.Parse(name))
+		require.True(t, ok)
+		require.Equal(t, expected, value, name)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := keyfile.Parse(strings.NewReader(`Key=outside
+[Group
+[Group]
+no separator
+=empty key
+Name[fr=broken
+Valid=value
+`))
+	require.Error(t, err)
+
+	var lines []int
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var syntaxErr *keyfile.SyntaxError
+		require.True(t, errors.As(err, &syntaxErr))
+		lines = append(lines, syntaxErr.Line)
+	}
+	require.Equal(t, []int{1, 2, 4, 5, 6}, lines)
+}
+
+func TestParseFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.desktop")
+	require.NoError(t, os.WriteFile(name, []byte(content), 0o600))
+
+	kf, err := keyfile.ParseFile(name)
+	require.NoError(t, err)
+	require.Len(t, kf.Groups(), 2)
+
+	_, err = keyfile.ParseFile(filepath.Join(t.TempDir(), "missing.desktop"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestEdit(t *testing.T) {
+	kf, err := keyfile.Parse(strings.NewReader(content))
+	require.NoError(t, err)
+
+	group := kf.Group("Desktop Entry")
+	group.Set("Exec", "editor --new %F")
+	group.Set("Terminal", "false")
+	group.SetLocale("Name", "de", "Bearbeiter")
+	require.True(t, group.Delete("Comment"))
+	require.False(t, group.Delete("Comment"))
+	require.True(t, group.DeleteLocale("Name", "sr@latin"))
+
+	kf.RemoveGroup("Desktop Action new")
+	kf.AddGroup("Extra").Set("Key", "value")
+	require.Equal(t, kf.Group("Extra"), kf.AddGroup("Extra"))
+
+	var sb strings.Builder
+	_, err = kf.WriteTo(&sb)
+	require.NoError(t, err)
+	require.Equal(t, `# Leading comment
+
+[Desktop Entry]
+Name=Editor
+Name[fr]=Éditeur
+# Inner comment
+Exec=editor --new %F
+Terminal=false
+Name[de]=Bearbeiter
+
+[Extra]
+Key=value
+`, sb.String())
+
+	// Test writing new key files.
+	kf = keyfile.New()
+	kf.AddGroup("First").Set("A", "1")
+	kf.AddGroup("Second").Set("B", "2")
+
+	sb.Reset()
+	_, err = kf.WriteTo(&sb)
+	require.NoError(t, err)
+	require.Equal(t, "[First]\nA=1\n\n[Second]\nB=2\n", sb.String())
+}
+
+func TestValues(t *testing.T) {
+	// Test strings.
+	require.Equal(t, " a\nb\tc\rd\\e\\x", keyfile.String(`\sa\nb\tc\rd\\e\x`))
+	require.Equal(t, "plain", keyfile.String("plain"))
+	require.Equal(t, `\sa\nb\tc\rd\\e`, keyfile.Escape(" a\nb\tc\rd\\e"))
+	require.Equal(t, "a b", keyfile.String(keyfile.Escape("a b")))
+
+	// Test lists.
+	require.Equal(t, []string{"a", "b;c", "d e"}, keyfile.List(`a;b\;c;d\se;`))
+	require.Equal(t, []string{"a", "b"}, keyfile.List("a;;b"))
+	require.Nil(t, keyfile.List(""))
+	require.Equal(t, `a;b\;c;d e;`, keyfile.JoinList([]string{"a", "b;c", "d e"}))
+	require.Equal(t, []string{"x;y", "z"}, keyfile.List(keyfile.JoinList([]string{"x;y", "z"})))
+
+	// Test booleans.
+	for value, expected := range map[string]bool{"true": true, "1": true, "false": false, "0": false} {
+		b, err := keyfile.Bool(value)
+		require.NoError(t, err)
+		require.Equal(t, expected, b)
+	}
+	_, err := keyfile.Bool("yes")
+	require.Error(t, err)
+}
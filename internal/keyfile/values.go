@@ -0,0 +1,131 @@
+package keyfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// String unescapes the specified raw string value. The \s, \n, \t, \r and \\
+// escape sequences are replaced by a space, newline, tab, carriage return
+// and backslash, respectively. Unknown escape sequences are preserved.
+func String(value string) string {
+	if strings.IndexByte(value, '\\') < 0 {
+		return value
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '\\' || i+1 == len(value) {
+			sb.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch value[i] {
+		case 's':
+			sb.WriteByte(' ')
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case '\\':
+			sb.WriteByte('\\')
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte(value[i])
+		}
+	}
+
+	return sb.String()
+}
+
+// Escape returns the raw representation of the specified string value.
+// It is the inverse of String. Leading spaces are escaped as \s, so that
+// they are preserved when the value is parsed.
+func Escape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case ' ':
+			if sb.Len() == 0 {
+				sb.WriteString(`\s`)
+			} else {
+				sb.WriteByte(c)
+			}
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\\':
+			sb.WriteString(`\\`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
+
+// List splits the specified raw value into its semicolon separated items.
+// Escaped semicolons (\;) are part of the items, which are unescaped using
+// String. The trailing semicolon is optional and empty items are ignored.
+func List(value string) []string {
+	var (
+		items []string
+		sb    strings.Builder
+	)
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '\\' && i+1 < len(value):
+			i++
+			if value[i] == ';' {
+				sb.WriteByte(';')
+			} else {
+				sb.WriteByte(c)
+				sb.WriteByte(value[i])
+			}
+		case c == ';':
+			if sb.Len() > 0 {
+				items = append(items, String(sb.String()))
+			}
+			sb.Reset()
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	if sb.Len() > 0 {
+		items = append(items, String(sb.String()))
+	}
+
+	return items
+}
+
+// JoinList returns the raw representation of the specified list of items.
+// It is the inverse of List. Each item is terminated by a semicolon.
+func JoinList(items []string) string {
+	var sb strings.Builder
+	for _, item := range items {
+		sb.WriteString(strings.ReplaceAll(Escape(item), ";", `\;`))
+		sb.WriteByte(';')
+	}
+
+	return sb.String()
+}
+
+// Bool parses the specified raw boolean value. The values true and 1 are
+// interpreted as true, while false and 0 are interpreted as false.
+func Bool(value string) (bool, error) {
+	switch value {
+	case "true", "1":
+		return true, nil
+	case "false", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid boolean value `%s`", value)
+	}
+}
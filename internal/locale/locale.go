@@ -0,0 +1,83 @@
+package locale
+
+import (
+	"os"
+	"strings"
+)
+
+// Locale contains the components of a POSIX locale name, in the
+// lang_COUNTRY.ENCODING@MODIFIER format (e.g. sr_RS.UTF-8@latin).
+type Locale struct {
+	Lang     string
+	Country  string
+	Encoding string
+	Modifier string
+}
+
+// Parse splits the specified POSIX locale name into its components.
+// The C and POSIX locales result in an empty locale.
+func Parse(name string) Locale {
+	var l Locale
+	if name = strings.TrimSpace(name); name == "C" || name == "POSIX" || strings.HasPrefix(name, "C.") {
+		return l
+	}
+
+	name, l.Modifier, _ = strings.Cut(name, "@")
+	name, l.Encoding, _ = strings.Cut(name, ".")
+	l.Lang, l.Country, _ = strings.Cut(name, "_")
+
+	return l
+}
+
+// Messages returns the locale used for messages, as defined by the LC_ALL,
+// LC_MESSAGES and LANG environment variables, in this order of precedence.
+func Messages() Locale {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return Parse(value)
+		}
+	}
+
+	return Locale{}
+}
+
+// IsZero returns true if the locale does not specify a language.
+func (l Locale) IsZero() bool {
+	return l.Lang == ""
+}
+
+// String returns the name of the locale, without the encoding
+// (e.g. sr_RS@latin).
+func (l Locale) String() string {
+	name := l.Lang
+	if l.Country != "" {
+		name += "_" + l.Country
+	}
+	if l.Modifier != "" {
+		name += "@" + l.Modifier
+	}
+
+	return name
+}
+
+// Candidates returns the locale names which match the locale, in order of
+// preference: lang_COUNTRY@MODIFIER, lang_COUNTRY, lang@MODIFIER and lang.
+// Only the names applicable to the components of the locale are returned.
+func (l Locale) Candidates() []string {
+	if l.IsZero() {
+		return nil
+	}
+
+	var candidates []string
+	if l.Country != "" {
+		if l.Modifier != "" {
+			candidates = append(candidates, l.Lang+"_"+l.Country+"@"+l.Modifier)
+		}
+		candidates = append(candidates, l.Lang+"_"+l.Country)
+	}
+	if l.Modifier != "" {
+		candidates = append(candidates, l.Lang+"@"+l.Modifier)
+	}
+
+	return append(candidates, l.Lang)
+}
@@ -0,0 +1,46 @@
+package locale_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adrg/xdg/internal/locale"
+)
+
+func TestParse(t *testing.T) {
+	l := locale.Parse("sr_RS.UTF-8@latin")
+	require.Equal(t, locale.Locale{Lang: "sr", Country: "RS", Encoding: "UTF-8", Modifier: "latin"}, l)
+	require.Equal(t, "sr_RS@latin", l.String())
+	require.Equal(t, []string{"sr_RS@latin", "sr_RS", "sr@latin", "sr"}, l.Candidates())
+
+	l = locale.Parse("fr_FR.UTF-8")
+	require.Equal(t, "fr_FR", l.String())
+	require.Equal(t, []string{"fr_FR", "fr"}, l.Candidates())
+
+	l = locale.Parse("de@euro")
+	require.Equal(t, []string{"de@euro", "de"}, l.Candidates())
+
+	for _, name := range []string{"", "C", "POSIX", "C.UTF-8"} {
+		l = locale.Parse(name)
+		require.True(t, l.IsZero())
+		require.Empty(t, l.String())
+		require.Empty(t, l.Candidates())
+	}
+}
+
+func TestMessages(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "")
+	require.True(t, locale.Messages().IsZero())
+
+	t.Setenv("LANG", "de_DE.UTF-8")
+	require.Equal(t, "de_DE", locale.Messages().String())
+
+	t.Setenv("LC_MESSAGES", "fr_FR.UTF-8")
+	require.Equal(t, "fr_FR", locale.Messages().String())
+
+	t.Setenv("LC_ALL", "es_ES.UTF-8")
+	require.Equal(t, "es_ES", locale.Messages().String())
+}
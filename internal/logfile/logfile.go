@@ -0,0 +1,287 @@
+package logfile
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat defines the format of the timestamp included in the names
+// of rotated log files. The format sorts lexicographically in chronological
+// order, which is relied upon when pruning old backups.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// compressSuffix is appended to the names of compressed log files.
+const compressSuffix = ".gz"
+
+// Options defines the rotation settings of a log file.
+type Options struct {
+	// MaxSize defines the maximum size in bytes of the log file before it
+	// gets rotated. A value of zero disables size based rotation.
+	MaxSize int64
+
+	// MaxAge defines the maximum amount of time the log file is written to
+	// before it gets rotated. A value of zero disables age based rotation.
+	MaxAge time.Duration
+
+	// MaxBackups defines the maximum number of rotated log files to retain.
+	// Older log files are removed first. A value of zero retains all rotated
+	// log files.
+	MaxBackups int
+
+	// Compress specifies whether rotated log files are compressed using gzip.
+	Compress bool
+}
+
+// File is an io.WriteCloser which writes to the log file at the specified
+// location and rotates it based on the provided options. It is safe for
+// concurrent use.
+type File struct {
+	name string
+	opts Options
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// Open opens the log file with the specified name for appending, creating it
+// if it does not exist. The parent directories of the file must exist.
+func Open(name string, opts Options) (*File, error) {
+	f := &File{name: name, opts: opts}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Name returns the path of the log file.
+func (f *File) Name() string {
+	return f.name
+}
+
+// Write writes the specified bytes to the log file. The log file is rotated
+// before writing if the write would cause it to exceed the configured maximum
+// size, or if it has been written to for longer than the configured maximum
+// age.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current log file, moves it aside and opens a new one.
+func (f *File) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.rotate()
+}
+
+// Sync commits the contents of the log file to stable storage.
+func (f *File) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// Close closes the log file. Subsequent writes reopen it.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.close()
+}
+
+func (f *File) open() error {
+	file, err := os.OpenFile(f.name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+
+	fi, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	// Age pre-existing log files from their last modification time, so that
+	// stale files are rotated on the first write.
+	f.file, f.size, f.openedAt = file, fi.Size(), time.Now()
+	if f.size > 0 && fi.ModTime().Before(f.openedAt) {
+		f.openedAt = fi.ModTime()
+	}
+
+	return nil
+}
+
+func (f *File) close() error {
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *File) shouldRotate(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.opts.MaxSize > 0 && f.size+n > f.opts.MaxSize {
+		return true
+	}
+
+	return f.opts.MaxAge > 0 && time.Since(f.openedAt) >= f.opts.MaxAge
+}
+
+func (f *File) rotate() error {
+	if err := f.close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(f.name, f.backupName(time.Now())); err != nil &&
+		!errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	return f.prune()
+}
+
+// backupName returns an unused name for a rotated log file, derived from the
+// name of the log file and the specified time.
+func (f *File) backupName(t time.Time) string {
+	prefix, ext := f.backupAffixes()
+	for {
+		name := prefix + t.Format(backupTimeFormat) + ext
+		if !exists(name) && !exists(name+compressSuffix) {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func (f *File) backupAffixes() (string, string) {
+	ext := filepath.Ext(f.name)
+	return strings.TrimSuffix(f.name, ext) + "-", ext
+}
+
+// backups returns the rotated log files, sorted from newest to oldest.
+func (f *File) backups() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(f.name))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, ext := f.backupAffixes()
+	prefix = filepath.Base(prefix)
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		ts := strings.TrimSuffix(strings.TrimSuffix(name, compressSuffix), ext)
+		if _, err := time.Parse(backupTimeFormat, ts[len(prefix):]); err != nil {
+			continue
+		}
+		names = append(names, filepath.Join(filepath.Dir(f.name), name))
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names, nil
+}
+
+// prune removes the rotated log files which exceed the configured number of
+// backups, and compresses the remaining ones, if requested.
+func (f *File) prune() error {
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i, name := range backups {
+		if f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups {
+			errs = append(errs, os.Remove(name))
+			continue
+		}
+		if f.opts.Compress && !strings.HasSuffix(name, compressSuffix) {
+			errs = append(errs, compress(name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func compress(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+
+	dst, err := os.OpenFile(name+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(dst.Name())
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err != nil {
+		return fmt.Errorf("could not compress log file `%s`: %w", name, err)
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(name)
+}
+
+func exists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
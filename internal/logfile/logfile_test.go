@@ -0,0 +1,131 @@
+package logfile_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adrg/xdg/internal/logfile"
+)
+
+func readBackups(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var backups []string
+	for _, entry := range entries {
+		if name := entry.Name(); strings.HasPrefix(name, "app-") {
+			backups = append(backups, name)
+		}
+	}
+
+	return backups
+}
+
+func TestSizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+
+	f, err := logfile.Open(name, logfile.Options{MaxSize: 10, MaxBackups: 2})
+	require.NoError(t, err)
+	require.Equal(t, name, f.Name())
+
+	// Test writes which fit in the log file.
+	_, err = f.Write([]byte("01234"))
+	require.NoError(t, err)
+	_, err = f.Write([]byte("56789"))
+	require.NoError(t, err)
+	require.Empty(t, readBackups(t, dir))
+
+	// Test writes which cause rotation.
+	for i := 0; i < 4; i++ {
+		_, err = f.Write([]byte("abcdefgh"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	require.Equal(t, "abcdefgh", string(data))
+
+	backups := readBackups(t, dir)
+	require.Len(t, backups, 2)
+	for _, backup := range backups {
+		require.True(t, strings.HasSuffix(backup, ".log"))
+	}
+
+	// Test writing after close.
+	_, err = f.Write([]byte("ijkl"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.Len(t, readBackups(t, dir), 2)
+}
+
+func TestAgeRotation(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+
+	// Test stale pre-existing log files.
+	require.NoError(t, os.WriteFile(name, []byte("stale"), 0o600))
+	staleTime := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(name, staleTime, staleTime))
+
+	f, err := logfile.Open(name, logfile.Options{MaxAge: time.Hour})
+	require.NoError(t, err)
+
+	_, err = f.Write([]byte("fresh"))
+	require.NoError(t, err)
+	require.Len(t, readBackups(t, dir), 1)
+
+	// Test recent log files.
+	_, err = f.Write([]byte("fresh"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.Len(t, readBackups(t, dir), 1)
+
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	require.Equal(t, "freshfresh", string(data))
+}
+
+func TestCompression(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+
+	f, err := logfile.Open(name, logfile.Options{Compress: true})
+	require.NoError(t, err)
+
+	_, err = f.Write([]byte("compressed"))
+	require.NoError(t, err)
+	require.NoError(t, f.Rotate())
+	require.NoError(t, f.Sync())
+	require.NoError(t, f.Close())
+
+	backups := readBackups(t, dir)
+	require.Len(t, backups, 1)
+	require.True(t, strings.HasSuffix(backups[0], ".log.gz"))
+
+	bf, err := os.Open(filepath.Join(dir, backups[0]))
+	require.NoError(t, err)
+	defer func() {
+		_ = bf.Close()
+	}()
+
+	zr, err := gzip.NewReader(bf)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	require.Equal(t, "compressed", string(data))
+}
+
+func TestOpenInvalidPath(t *testing.T) {
+	_, err := logfile.Open(filepath.Join(t.TempDir(), "missing", "app.log"), logfile.Options{})
+	require.Error(t, err)
+}
@@ -4,16 +4,63 @@ package userdirs
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"unicode"
 
+	"github.com/adrg/xdg/internal/fileutil"
 	"github.com/adrg/xdg/internal/pathutil"
 )
 
+// configHeader is written at the beginning of newly created user directories
+// config files.
+const configHeader = `# This file is written by xdg-user-dirs-update
+# If you want to change or add directories, just edit the line you're
+# interested in. All local changes will be retained on the next run.
+# Format is XDG_xxx_DIR="$HOME/yyy", where yyy is a shell-escaped
+# homedir-relative path, or XDG_xxx_DIR="/yyy", where /yyy is an
+# absolute path. No other format is supported.
+#
+`
+
 // ParseConfigFile parses the user directories config file at the
 // specified location.
 func ParseConfigFile(name string) (*Directories, error) {
+	return parseConfigFile(name, false)
+}
+
+// ParseConfigFileStrict parses the user directories config file at the
+// specified location in strict mode. See ParseConfigStrict for more details.
+func ParseConfigFileStrict(name string) (*Directories, error) {
+	return parseConfigFile(name, true)
+}
+
+// ParseConfig parses the user directories config file contained in
+// the provided reader. Malformed lines are ignored. Entries which do not
+// correspond to well known user directories are stored as custom
+// user directories.
+func ParseConfig(r io.Reader) (*Directories, error) {
+	return parseConfig(r, false)
+}
+
+// ParseConfigStrict parses the user directories config file contained in
+// the provided reader in strict mode. Apart from comments and blank lines,
+// the file must only contain lines in the XDG_xxx_DIR="$HOME/yyy" format,
+// where yyy is a shell-escaped path relative to the home directory, or in the
+// XDG_xxx_DIR="/yyy" format, where /yyy is a shell-escaped absolute path.
+// All malformed lines are reported as *SyntaxError values, joined in the
+// returned error.
+func ParseConfigStrict(r io.Reader) (*Directories, error) {
+	return parseConfig(r, true)
+}
+
+func parseConfigFile(name string, strict bool) (*Directories, error) {
 	f, err := os.Open(name)
 	if err != nil {
 		return nil, err
@@ -22,12 +69,10 @@ func ParseConfigFile(name string) (*Directories, error) {
 		_ = f.Close()
 	}()
 
-	return ParseConfig(f)
+	return parseConfig(f, strict)
 }
 
-// ParseConfig parses the user directories config file contained in
-// the provided reader.
-func ParseConfig(r io.Reader) (*Directories, error) {
+func parseConfig(r io.Reader, strict bool) (*Directories, error) {
 	dirs := &Directories{}
 	fieldsMap := map[string]*string{
 		EnvDesktopDir:     &dirs.Desktop,
@@ -40,45 +85,259 @@ func ParseConfig(r io.Reader) (*Directories, error) {
 		EnvPublicShareDir: &dirs.PublicShare,
 	}
 
+	var (
+		custom  = map[string]string{}
+		errs    []error
+		lineNum int
+	)
+
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if len(line) == 0 || line[0] == '#' {
+		lineNum++
+
+		key, value, err := parseLine(scanner.Text(), strict)
+		if err != nil {
+			if strict {
+				errs = append(errs, &SyntaxError{Line: lineNum, Msg: err.Error()})
+			}
 			continue
 		}
-		if !strings.HasPrefix(line, "XDG_") {
+		if key == "" {
 			continue
 		}
 
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) < 2 {
-			continue
+		if field, ok := fieldsMap[key]; ok {
+			*field = value
+		} else if validKey(key) {
+			custom[strings.TrimSuffix(strings.TrimPrefix(key, "XDG_"), "_DIR")] = value
 		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+	SetCustom(dirs, custom)
 
-		// Parse key.
-		field, ok := fieldsMap[strings.TrimSpace(parts[0])]
-		if !ok {
-			continue
+	return dirs, nil
+}
+
+// parseLine parses the specified config file line. An empty key is returned
+// for comments, blank lines and lines which are not user directory entries.
+// In strict mode, only the entry format written by xdg-user-dirs-update is
+// accepted. Otherwise, leading and trailing content is tolerated and paths
+// relative to `~` are also accepted.
+func parseLine(line string, strict bool) (string, string, error) {
+	trimmed := strings.TrimSpace(line)
+	if len(trimmed) == 0 || trimmed[0] == '#' {
+		return "", "", nil
+	}
+	if !strict && !strings.HasPrefix(trimmed, "XDG_") {
+		return "", "", nil
+	}
+
+	// Parse key.
+	key, value, ok := strings.Cut(trimmed, "=")
+	if !ok {
+		return "", "", errors.New("missing `=` separator")
+	}
+	if strict {
+		if !validKey(key) {
+			return "", "", fmt.Errorf("invalid key `%s`: expected XDG_xxx_DIR", key)
 		}
+	} else {
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	}
 
-		// Parse value.
-		runes := []rune(strings.TrimSpace(parts[1]))
+	// Parse value.
+	if len(value) == 0 || value[0] != '"' {
+		return "", "", errors.New("value must be enclosed in double quotes")
+	}
 
-		lenRunes := len(runes)
-		if lenRunes <= 2 || runes[0] != '"' {
-			continue
+	raw, path, rest, ok := unquote(value[1:])
+	if !ok {
+		return "", "", errors.New("missing closing double quote")
+	}
+	if !strict {
+		if path == "" {
+			return "", "", errors.New("empty path")
+		}
+		return key, pathutil.ExpandHome(path), nil
+	}
+	if trimmed := strings.TrimSpace(rest); trimmed != "" && (trimmed[0] != '#' || !unicode.IsSpace(rune(rest[0]))) {
+		return "", "", fmt.Errorf("unexpected content after value: %s", trimmed)
+	}
+
+	switch {
+	case raw == "$HOME" || strings.HasPrefix(raw, "$HOME/"):
+		return key, pathutil.ExpandHome(path), nil
+	case strings.HasPrefix(path, "/"):
+		return key, path, nil
+	case path == "":
+		return "", "", errors.New("empty path")
+	default:
+		return "", "", fmt.Errorf("invalid path `%s`: must be absolute or start with $HOME/", raw)
+	}
+}
+
+// unquote reads the shell double-quoted string at the start of the specified
+// value, which must not include the opening double quote. It returns the raw
+// quoted content, the unescaped content and the remaining part of the value,
+// after the closing double quote.
+func unquote(value string) (string, string, string, bool) {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '"':
+			return value[:i], sb.String(), value[i+1:], true
+		case '\\':
+			if i+1 < len(value) && strings.IndexByte("\"$`\\", value[i+1]) >= 0 {
+				i++
+				c = value[i]
+			}
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return "", "", "", false
+}
+
+// validKey returns true if the specified key is in the XDG_xxx_DIR format,
+// where xxx consists of uppercase letters, digits and underscores.
+func validKey(key string) bool {
+	name, ok := strings.CutPrefix(key, "XDG_")
+	if !ok {
+		return false
+	}
+	if name, ok = strings.CutSuffix(name, "_DIR"); !ok || name == "" {
+		return false
+	}
+
+	return strings.Trim(name, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_") == ""
+}
+
+// UpdateConfigFile sets the locations of the specified user directories in
+// the user directories config file at the specified location. The file is
+// created if it does not exist. See UpdateConfig for more details.
+func UpdateConfigFile(name string, dirs map[string]string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		data = []byte(configHeader)
+	}
+
+	var buf bytes.Buffer
+	if err := UpdateConfig(bytes.NewReader(data), &buf, dirs); err != nil {
+		return err
+	}
+
+	return fileutil.WriteAtomic(name, buf.Bytes(), 0o644)
+}
+
+// UpdateConfig copies the user directories config file contained in the
+// provided reader to the provided writer, setting the locations of the
+// specified user directories. The keys of the dirs map must be the names
+// of the environment variables of the user directories (e.g. XDG_MUSIC_DIR),
+// and the values must be absolute paths. Existing entries are updated in
+// place, while new entries are appended to the end of the file. Comments
+// and unrelated lines are preserved. The locations are written in the
+// format used by xdg-user-dirs-update: XDG_xxx_DIR="$HOME/yyy" for locations
+// inside the home directory and XDG_xxx_DIR="/yyy" for the rest of them.
+func UpdateConfig(r io.Reader, w io.Writer, dirs map[string]string) error {
+	values := make(map[string]string, len(dirs))
+	for key, dir := range dirs {
+		if !strings.HasPrefix(key, "XDG_") || !strings.HasSuffix(key, "_DIR") {
+			return fmt.Errorf("invalid user directory key `%s`", key)
 		}
+		if dir = pathutil.ExpandHome(dir); !filepath.IsAbs(dir) {
+			return fmt.Errorf("user directory `%s` must be an absolute path: %s", key, dir)
+		}
+		values[key] = formatPath(dir)
+	}
+
+	var (
+		buf     bytes.Buffer
+		written = map[string]bool{}
+	)
 
-		for i := 1; i < lenRunes; i++ {
-			if runes[i] == '"' {
-				*field = pathutil.ExpandHome(string(runes[1:i]))
-				break
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if key, ok := lineKey(line); ok {
+			if value, ok := values[key]; ok {
+				if !written[key] {
+					fmt.Fprintf(&buf, "%s=\"%s\"\n", key, value)
+					written[key] = true
+				}
+				continue
 			}
 		}
+
+		buf.WriteString(line)
+		buf.WriteByte('\n')
 	}
 	if err := scanner.Err(); err != nil {
-		return nil, err
+		return err
 	}
 
-	return dirs, nil
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if !written[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "%s=\"%s\"\n", key, values[key])
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// lineKey returns the key of the entry contained in the specified config
+// file line, if any.
+func lineKey(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 || line[0] == '#' {
+		return "", false
+	}
+
+	key, _, ok := strings.Cut(line, "=")
+	return strings.TrimSpace(key), ok
+}
+
+// formatPath returns the config file representation of the specified path.
+// Paths inside the home directory are written relative to $HOME.
+func formatPath(path string) string {
+	home := pathutil.UserHomeDir()
+	if rel, err := filepath.Rel(home, path); err == nil &&
+		rel != ".." && !strings.HasPrefix(rel, "../") {
+		if rel == "." {
+			rel = ""
+		}
+		return "$HOME/" + shellEscape(rel)
+	}
+
+	return shellEscape(path)
+}
+
+// shellEscape escapes the characters which have a special meaning inside
+// double-quoted shell strings.
+func shellEscape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '"', '$', '`', '\\':
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
 }
@@ -3,6 +3,8 @@
 package userdirs_test
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -85,3 +87,160 @@ func TestParseConfig(t *testing.T) {
 	require.Nil(t, dirs)
 	require.NoError(t, os.Remove(f.Name()))
 }
+
+func TestUpdateConfig(t *testing.T) {
+	home := pathutil.UserHomeDir()
+
+	input := `# Header comment
+XDG_DESKTOP_DIR="$HOME/Desktop"
+XDG_DOWNLOAD_DIR="$HOME/Downloads" # Old location
+XDG_PROJECTS_DIR="$HOME/Projects"
+UNRELATED=value
+XDG_DOWNLOAD_DIR="$HOME/Duplicate"
+`
+	var buf strings.Builder
+	err := userdirs.UpdateConfig(strings.NewReader(input), &buf, map[string]string{
+		userdirs.EnvDownloadDir:      filepath.Join(home, "My \"Downloads\""),
+		userdirs.EnvMusicDir:         "/srv/music $1",
+		userdirs.EnvVideosDir:        home,
+		userdirs.EnvName("PROJECTS"): "~/Code",
+	})
+	require.NoError(t, err)
+	require.Equal(t, `# Header comment
+XDG_DESKTOP_DIR="$HOME/Desktop"
+XDG_DOWNLOAD_DIR="$HOME/My \"Downloads\""
+XDG_PROJECTS_DIR="$HOME/Code"
+UNRELATED=value
+XDG_MUSIC_DIR="/srv/music \$1"
+XDG_VIDEOS_DIR="$HOME/"
+`, buf.String())
+
+	// Test round trip.
+	dirs, err := userdirs.ParseConfig(strings.NewReader(buf.String()))
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(home, "Desktop"), dirs.Desktop)
+
+	// Test invalid entries.
+	err = userdirs.UpdateConfig(strings.NewReader(input), &buf, map[string]string{
+		"DOWNLOAD": "/tmp/downloads",
+	})
+	require.Error(t, err)
+
+	err = userdirs.UpdateConfig(strings.NewReader(input), &buf, map[string]string{
+		userdirs.EnvDownloadDir: "downloads",
+	})
+	require.Error(t, err)
+}
+
+func TestUpdateConfigFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "user-dirs.dirs")
+
+	// Test non-existent file.
+	require.NoError(t, userdirs.UpdateConfigFile(name, map[string]string{
+		userdirs.EnvDownloadDir: "/tmp/downloads",
+	}))
+
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(data), "# This file is written by xdg-user-dirs-update"))
+	require.True(t, strings.HasSuffix(string(data), "#\nXDG_DOWNLOAD_DIR=\"/tmp/downloads\"\n"))
+
+	// Test existing file.
+	require.NoError(t, userdirs.UpdateConfigFile(name, map[string]string{
+		userdirs.EnvDownloadDir: "/tmp/other",
+	}))
+
+	dirs, err := userdirs.ParseConfigFile(name)
+	require.NoError(t, err)
+	require.Equal(t, "/tmp/other", dirs.Download)
+
+	// Test invalid path.
+	require.Error(t, userdirs.UpdateConfigFile(filepath.Join(name, "invalid"), nil))
+}
+
+func TestParseConfigStrict(t *testing.T) {
+	home := pathutil.UserHomeDir()
+
+	// Test valid config file.
+	dirs, err := userdirs.ParseConfigStrict(strings.NewReader(`# Comment
+
+XDG_DESKTOP_DIR="$HOME/Desktop"
+XDG_DOWNLOAD_DIR="$HOME/My \"Downloads\" \$1"
+  XDG_TEMPLATES_DIR="/home/test/Templates"   # Templates
+XDG_MUSIC_DIR="$HOME"
+XDG_PROJECTS_DIR="$HOME/Projects"
+`))
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(home, "Desktop"), dirs.Desktop)
+	require.Equal(t, filepath.Join(home, `My "Downloads" $1`), dirs.Download)
+	require.Equal(t, "/home/test/Templates", dirs.Templates)
+	require.Equal(t, home, dirs.Music)
+
+	// Test invalid config file.
+	_, err = userdirs.ParseConfigStrict(strings.NewReader(`XDG_DESKTOP_DIR="$HOME/Desktop"
+XDG_DOWNLOAD_DIR="~/Downloads"
+XDG_MUSIC_DIR = "$HOME/Music"
+XDG_VIDEOS_DIR=$HOME/Videos
+XDG_PICTURES_DIR="$HOME/Pictures
+XDG_PUBLICSHARE_DIR="$HOMEDIR/Public"
+NON_XDG_DIR="/ignore"
+XDG_TEMPLATES_DIR
+XDG_DOCUMENTS_DIR="/home/test/Documents" Documents
+XDG_DOCUMENTS_DIR="/home/test/Documents"#Documents
+XDG_DOCUMENTS_DIR=""
+`))
+	require.Error(t, err)
+
+	var syntaxErrs []int
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var syntaxErr *userdirs.SyntaxError
+		require.ErrorAs(t, err, &syntaxErr)
+		require.Contains(t, syntaxErr.Error(), fmt.Sprintf("line %d:", syntaxErr.Line))
+		syntaxErrs = append(syntaxErrs, syntaxErr.Line)
+	}
+	require.Equal(t, []int{2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, syntaxErrs)
+
+	// Test lenient mode.
+	dirs, err = userdirs.ParseConfig(strings.NewReader(`XDG_DOWNLOAD_DIR="$HOME/My \"Downloads\""`))
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(home, `My "Downloads"`), dirs.Download)
+}
+
+func TestParseConfigFileStrict(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "user-dirs.dirs")
+	require.NoError(t, os.WriteFile(name, []byte(`XDG_DOWNLOAD_DIR="/home/test/Downloads"`), 0o600))
+
+	dirs, err := userdirs.ParseConfigFileStrict(name)
+	require.NoError(t, err)
+	require.Equal(t, "/home/test/Downloads", dirs.Download)
+
+	// Test invalid file.
+	require.NoError(t, os.WriteFile(name, []byte(`XDG_DOWNLOAD_DIR="Downloads"`), 0o600))
+
+	_, err = userdirs.ParseConfigFileStrict(name)
+	require.Error(t, err)
+
+	// Test non-existent file.
+	_, err = userdirs.ParseConfigFileStrict(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
+
+func TestParseConfigCustom(t *testing.T) {
+	home := pathutil.UserHomeDir()
+
+	for _, parse := range []func(io.Reader) (*userdirs.Directories, error){
+		userdirs.ParseConfig,
+		userdirs.ParseConfigStrict,
+	} {
+		dirs, err := parse(strings.NewReader(`XDG_DOWNLOAD_DIR="$HOME/Downloads"
+XDG_PROJECTS_DIR="$HOME/Projects"
+XDG_SCREENSHOTS_DIR="/srv/screenshots"
+`))
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(home, "Downloads"), dirs.Download)
+		require.Equal(t, map[string]string{
+			"PROJECTS":    filepath.Join(home, "Projects"),
+			"SCREENSHOTS": "/srv/screenshots",
+		}, dirs.Custom())
+	}
+}
@@ -0,0 +1,148 @@
+//go:build aix || dragonfly || freebsd || (js && wasm) || nacl || linux || netbsd || openbsd || solaris
+
+package userdirs
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// Names of the files used to configure the user directories.
+const (
+	ConfigFileName   = "user-dirs.dirs"
+	DefaultsFileName = "user-dirs.defaults"
+	SettingsFileName = "user-dirs.conf"
+)
+
+// DefaultNames contains the default locations of the well known user
+// directories, relative to the home directory, indexed by user directory
+// name. The defaults are used if no user directories defaults file exists.
+var DefaultNames = map[string]string{
+	"DESKTOP":     "Desktop",
+	"DOWNLOAD":    "Downloads",
+	"TEMPLATES":   "Templates",
+	"PUBLICSHARE": "Public",
+	"DOCUMENTS":   "Documents",
+	"MUSIC":       "Music",
+	"PICTURES":    "Pictures",
+	"VIDEOS":      "Videos",
+}
+
+// Settings contains the options of the user directories settings file.
+type Settings struct {
+	// Enabled specifies whether the user directories are managed
+	// automatically. If disabled, the user directories defaults file
+	// is not used.
+	Enabled bool
+
+	// FilenameEncoding contains the encoding used for the names of the user
+	// directories (e.g. UTF-8 or locale).
+	FilenameEncoding string
+}
+
+// ParseDefaultsFile parses the user directories defaults file at the
+// specified location.
+func ParseDefaultsFile(name string) (map[string]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	return ParseDefaults(f)
+}
+
+// ParseDefaults parses the user directories defaults file contained in the
+// provided reader. The file contains lines in the NAME=relative-path format
+// (e.g. DESKTOP=Desktop). The returned map contains the default locations,
+// relative to the home directory, indexed by user directory name.
+func ParseDefaults(r io.Reader) (map[string]string, error) {
+	defaults := map[string]string{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if name, value = strings.TrimSpace(name), strings.TrimSpace(value); name == "" || value == "" {
+			continue
+		}
+
+		defaults[strings.ToUpper(name)] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return defaults, nil
+}
+
+// ParseSettingsFiles parses the user directories settings files at the
+// specified locations. The files are applied in order, so the options
+// defined by later files take precedence. Non-existent files are ignored.
+func ParseSettingsFiles(names ...string) (*Settings, error) {
+	settings := &Settings{Enabled: true}
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		err = parseSettings(f, settings)
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return settings, nil
+}
+
+// ParseSettings parses the user directories settings file contained in the
+// provided reader.
+func ParseSettings(r io.Reader) (*Settings, error) {
+	settings := &Settings{Enabled: true}
+	if err := parseSettings(r, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func parseSettings(r io.Reader, settings *Settings) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		switch value = strings.TrimSpace(value); strings.TrimSpace(key) {
+		case "enabled":
+			settings.Enabled = !strings.EqualFold(value, "false")
+		case "filename_encoding":
+			settings.FilenameEncoding = value
+		}
+	}
+
+	return scanner.Err()
+}
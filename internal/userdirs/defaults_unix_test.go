@@ -0,0 +1,79 @@
+//go:build aix || dragonfly || freebsd || (js && wasm) || nacl || linux || netbsd || openbsd || solaris
+
+package userdirs_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adrg/xdg/internal/userdirs"
+)
+
+func TestParseDefaults(t *testing.T) {
+	defaults, err := userdirs.ParseDefaults(strings.NewReader(`
+		# Default settings for user directories
+		#
+		# The values are relative pathnames from the home directory and
+		# will be translated on a per-path-element basis into the users locale
+		DESKTOP=Desktop
+		DOWNLOAD=Downloads
+		TEMPLATES=Documents/Templates
+		music = Media/Music
+		PUBLICSHARE=
+		INVALID
+	`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"DESKTOP":   "Desktop",
+		"DOWNLOAD":  "Downloads",
+		"TEMPLATES": "Documents/Templates",
+		"MUSIC":     "Media/Music",
+	}, defaults)
+
+	// Test defaults file.
+	name := filepath.Join(t.TempDir(), userdirs.DefaultsFileName)
+	require.NoError(t, os.WriteFile(name, []byte("VIDEOS=Movies\n"), 0o600))
+
+	defaults, err = userdirs.ParseDefaultsFile(name)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"VIDEOS": "Movies"}, defaults)
+
+	// Test non-existent file.
+	_, err = userdirs.ParseDefaultsFile(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
+
+func TestParseSettings(t *testing.T) {
+	settings, err := userdirs.ParseSettings(strings.NewReader(`
+		# This controls the behaviour of xdg-user-dirs-update
+		enabled=False
+		filename_encoding=UTF-8
+	`))
+	require.NoError(t, err)
+	require.Equal(t, &userdirs.Settings{FilenameEncoding: "UTF-8"}, settings)
+
+	// Test settings files precedence.
+	var (
+		dir          = t.TempDir()
+		systemConfig = filepath.Join(dir, "system.conf")
+		userConfig   = filepath.Join(dir, "user.conf")
+	)
+	require.NoError(t, os.WriteFile(systemConfig, []byte("enabled=False\nfilename_encoding=locale\n"), 0o600))
+	require.NoError(t, os.WriteFile(userConfig, []byte("enabled=True\n"), 0o600))
+
+	settings, err = userdirs.ParseSettingsFiles(systemConfig, filepath.Join(dir, "missing"), userConfig)
+	require.NoError(t, err)
+	require.Equal(t, &userdirs.Settings{Enabled: true, FilenameEncoding: "locale"}, settings)
+
+	settings, err = userdirs.ParseSettingsFiles()
+	require.NoError(t, err)
+	require.True(t, settings.Enabled)
+
+	// Test invalid settings file.
+	_, err = userdirs.ParseSettingsFiles(dir)
+	require.Error(t, err)
+}
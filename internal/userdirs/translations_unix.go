@@ -0,0 +1,155 @@
+//go:build aix || dragonfly || freebsd || (js && wasm) || nacl || linux || netbsd || openbsd || solaris
+
+package userdirs
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/adrg/xdg/internal/locale"
+)
+
+// LocaleFileName is the name of the file in which xdg-user-dirs-update records
+// the locale used for creating the user directories.
+const LocaleFileName = "user-dirs.locale"
+
+// translations contains the translations of the default user directory names
+// used by xdg-user-dirs, indexed by locale.
+var translations = map[string]map[string]string{
+	"cs": {
+		"Desktop": "Plocha", "Downloads": "Stažené", "Templates": "Šablony", "Public": "Veřejné",
+		"Documents": "Dokumenty", "Music": "Hudba", "Pictures": "Obrázky", "Videos": "Videa",
+	},
+	"da": {
+		"Desktop": "Skrivebord", "Downloads": "Hentede filer", "Templates": "Skabeloner", "Public": "Offentlig",
+		"Documents": "Dokumenter", "Music": "Musik", "Pictures": "Billeder", "Videos": "Videoer",
+	},
+	"de": {
+		"Desktop": "Schreibtisch", "Downloads": "Downloads", "Templates": "Vorlagen", "Public": "Öffentlich",
+		"Documents": "Dokumente", "Music": "Musik", "Pictures": "Bilder", "Videos": "Videos",
+	},
+	"el": {
+		"Desktop": "Επιφάνεια εργασίας", "Downloads": "Λήψεις", "Templates": "Πρότυπα", "Public": "Δημόσιο",
+		"Documents": "Έγγραφα", "Music": "Μουσική", "Pictures": "Εικόνες", "Videos": "Βίντεο",
+	},
+	"es": {
+		"Desktop": "Escritorio", "Downloads": "Descargas", "Templates": "Plantillas", "Public": "Público",
+		"Documents": "Documentos", "Music": "Música", "Pictures": "Imágenes", "Videos": "Vídeos",
+	},
+	"fi": {
+		"Desktop": "Työpöytä", "Downloads": "Lataukset", "Templates": "Mallit", "Public": "Julkinen",
+		"Documents": "Asiakirjat", "Music": "Musiikki", "Pictures": "Kuvat", "Videos": "Videot",
+	},
+	"fr": {
+		"Desktop": "Bureau", "Downloads": "Téléchargements", "Templates": "Modèles", "Public": "Public",
+		"Documents": "Documents", "Music": "Musique", "Pictures": "Images", "Videos": "Vidéos",
+	},
+	"hu": {
+		"Desktop": "Asztal", "Downloads": "Letöltések", "Templates": "Sablonok", "Public": "Nyilvános",
+		"Documents": "Dokumentumok", "Music": "Zenék", "Pictures": "Képek", "Videos": "Videók",
+	},
+	"it": {
+		"Desktop": "Scrivania", "Downloads": "Scaricati", "Templates": "Modelli", "Public": "Pubblici",
+		"Documents": "Documenti", "Music": "Musica", "Pictures": "Immagini", "Videos": "Video",
+	},
+	"ja": {
+		"Desktop": "デスクトップ", "Downloads": "ダウンロード", "Templates": "テンプレート", "Public": "公開",
+		"Documents": "ドキュメント", "Music": "音楽", "Pictures": "画像", "Videos": "ビデオ",
+	},
+	"ko": {
+		"Desktop": "바탕화면", "Downloads": "다운로드", "Templates": "템플릿", "Public": "공개",
+		"Documents": "문서", "Music": "음악", "Pictures": "사진", "Videos": "비디오",
+	},
+	"nb": {
+		"Desktop": "Skrivebord", "Downloads": "Nedlastinger", "Templates": "Maler", "Public": "Offentlig",
+		"Documents": "Dokumenter", "Music": "Musikk", "Pictures": "Bilder", "Videos": "Videoer",
+	},
+	"nl": {
+		"Desktop": "Bureaublad", "Downloads": "Downloads", "Templates": "Sjablonen", "Public": "Openbaar",
+		"Documents": "Documenten", "Music": "Muziek", "Pictures": "Afbeeldingen", "Videos": "Video's",
+	},
+	"pl": {
+		"Desktop": "Pulpit", "Downloads": "Pobrane", "Templates": "Szablony", "Public": "Publiczny",
+		"Documents": "Dokumenty", "Music": "Muzyka", "Pictures": "Obrazy", "Videos": "Wideo",
+	},
+	"pt": {
+		"Desktop": "Ambiente de trabalho", "Downloads": "Transferências", "Templates": "Modelos", "Public": "Público",
+		"Documents": "Documentos", "Music": "Música", "Pictures": "Imagens", "Videos": "Vídeos",
+	},
+	"pt_BR": {
+		"Desktop": "Área de trabalho", "Downloads": "Downloads", "Templates": "Modelos", "Public": "Público",
+		"Documents": "Documentos", "Music": "Música", "Pictures": "Imagens", "Videos": "Vídeos",
+	},
+	"ru": {
+		"Desktop": "Рабочий стол", "Downloads": "Загрузки", "Templates": "Шаблоны", "Public": "Общедоступные",
+		"Documents": "Документы", "Music": "Музыка", "Pictures": "Изображения", "Videos": "Видео",
+	},
+	"sv": {
+		"Desktop": "Skrivbord", "Downloads": "Hämtningar", "Templates": "Mallar", "Public": "Publikt",
+		"Documents": "Dokument", "Music": "Musik", "Pictures": "Bilder", "Videos": "Videor",
+	},
+	"tr": {
+		"Desktop": "Masaüstü", "Downloads": "İndirilenler", "Templates": "Şablonlar", "Public": "Genel",
+		"Documents": "Belgeler", "Music": "Müzik", "Pictures": "Resimler", "Videos": "Videolar",
+	},
+	"uk": {
+		"Desktop": "Стільниця", "Downloads": "Завантаження", "Templates": "Шаблони", "Public": "Загальнодоступні",
+		"Documents": "Документи", "Music": "Музика", "Pictures": "Зображення", "Videos": "Відео",
+	},
+	"zh_CN": {
+		"Desktop": "桌面", "Downloads": "下载", "Templates": "模板", "Public": "公共",
+		"Documents": "文档", "Music": "音乐", "Pictures": "图片", "Videos": "视频",
+	},
+	"zh_TW": {
+		"Desktop": "桌面", "Downloads": "下載", "Templates": "範本", "Public": "公共",
+		"Documents": "文件", "Music": "音樂", "Pictures": "圖片", "Videos": "影片",
+	},
+}
+
+// ParseLocaleFile returns the locale recorded in the user directories locale
+// file at the specified location.
+func ParseLocaleFile(name string) (locale.Locale, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return locale.Locale{}, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && line[0] != '#' {
+			return locale.Parse(line), nil
+		}
+	}
+
+	return locale.Locale{}, scanner.Err()
+}
+
+// Translate translates the specified default location, relative to the home
+// directory, into the specified locale. As done by xdg-user-dirs-update, each
+// element of the path is translated individually (e.g. Documents/Templates
+// becomes Documenti/Modelli for Italian). Elements with no translation are
+// left unchanged.
+func Translate(relPath string, l locale.Locale) string {
+	var table map[string]string
+	for _, name := range l.Candidates() {
+		if table = translations[name]; table != nil {
+			break
+		}
+	}
+	if table == nil {
+		return relPath
+	}
+
+	elems := strings.Split(relPath, "/")
+	for i, elem := range elems {
+		if translated, ok := table[elem]; ok {
+			elems[i] = translated
+		}
+	}
+
+	return strings.Join(elems, "/")
+}
@@ -0,0 +1,47 @@
+//go:build aix || dragonfly || freebsd || (js && wasm) || nacl || linux || netbsd || openbsd || solaris
+
+package userdirs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adrg/xdg/internal/locale"
+	"github.com/adrg/xdg/internal/userdirs"
+)
+
+func TestTranslate(t *testing.T) {
+	require.Equal(t, "Téléchargements", userdirs.Translate("Downloads", locale.Parse("fr_FR.UTF-8")))
+	require.Equal(t, "Bilder", userdirs.Translate("Pictures", locale.Parse("de_AT")))
+	require.Equal(t, "Área de trabalho", userdirs.Translate("Desktop", locale.Parse("pt_BR.UTF-8")))
+	require.Equal(t, "Ambiente de trabalho", userdirs.Translate("Desktop", locale.Parse("pt_PT.UTF-8")))
+	require.Equal(t, "Documenti/Modelli", userdirs.Translate("Documents/Templates", locale.Parse("it_IT")))
+	require.Equal(t, "Documenti/Custom", userdirs.Translate("Documents/Custom", locale.Parse("it_IT")))
+
+	// Test locales with no translations.
+	require.Equal(t, "Downloads", userdirs.Translate("Downloads", locale.Parse("en_US.UTF-8")))
+	require.Equal(t, "Downloads", userdirs.Translate("Downloads", locale.Parse("C")))
+}
+
+func TestParseLocaleFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), userdirs.LocaleFileName)
+	require.NoError(t, os.WriteFile(name, []byte("de_DE\n"), 0o600))
+
+	l, err := userdirs.ParseLocaleFile(name)
+	require.NoError(t, err)
+	require.Equal(t, locale.Locale{Lang: "de", Country: "DE"}, l)
+
+	// Test empty file.
+	require.NoError(t, os.WriteFile(name, nil, 0o600))
+
+	l, err = userdirs.ParseLocaleFile(name)
+	require.NoError(t, err)
+	require.True(t, l.IsZero())
+
+	// Test non-existent file.
+	_, err = userdirs.ParseLocaleFile(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
@@ -1,5 +1,14 @@
 package userdirs
 
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/adrg/xdg/internal/pathutil"
+)
+
 // XDG user directories environment variables.
 const (
 	EnvDesktopDir     = "XDG_DESKTOP_DIR"
@@ -12,6 +21,18 @@ const (
 	EnvPublicShareDir = "XDG_PUBLICSHARE_DIR"
 )
 
+// wellKnownNames contains the names of the well known user directories.
+var wellKnownNames = []string{
+	"DESKTOP", "DOWNLOAD", "DOCUMENTS", "MUSIC",
+	"PICTURES", "VIDEOS", "TEMPLATES", "PUBLICSHARE",
+}
+
+// EnvName returns the name of the environment variable of the user directory
+// with the specified name (e.g. XDG_MUSIC_DIR for MUSIC).
+func EnvName(name string) string {
+	return "XDG_" + name + "_DIR"
+}
+
 // Directories defines the locations of well known user directories.
 type Directories struct {
 	// Desktop defines the location of the user's desktop directory.
@@ -37,4 +58,112 @@ type Directories struct {
 
 	// PublicShare defines a suitable location for user shared files.
 	PublicShare string
+
+	// custom contains the locations of the custom user directories, encoded
+	// as NUL separated name and location pairs, sorted by name. The string
+	// encoding keeps Directories comparable, with equal custom user
+	// directories resulting in equal values.
+	custom string
+}
+
+// SetCustom sets the locations of the custom user directories of d, indexed
+// by user directory name (e.g. PROJECTS).
+func SetCustom(d *Directories, custom map[string]string) {
+	var sb strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(custom)) {
+		sb.WriteString(name)
+		sb.WriteByte(0)
+		sb.WriteString(custom[name])
+		sb.WriteByte(0)
+	}
+
+	d.custom = sb.String()
+}
+
+// Custom returns the locations of the additional user directories, which are
+// not part of the well known set (e.g. XDG_PROJECTS_DIR), indexed by user
+// directory name (e.g. PROJECTS).
+func (d Directories) Custom() map[string]string {
+	if d.custom == "" {
+		return nil
+	}
+
+	fields := strings.Split(strings.TrimSuffix(d.custom, "\x00"), "\x00")
+
+	custom := make(map[string]string, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		custom[fields[i]] = fields[i+1]
+	}
+
+	return custom
+}
+
+// All returns the locations of all the defined user directories, both well
+// known and custom, indexed by user directory name (e.g. DOWNLOAD).
+func (d Directories) All() map[string]string {
+	custom := d.Custom()
+
+	dirs := make(map[string]string, len(wellKnownNames)+len(custom))
+	for _, name := range wellKnownNames {
+		if dir, ok := d.Lookup(name); ok {
+			dirs[name] = dir
+		}
+	}
+	for name, dir := range custom {
+		if dir != "" {
+			dirs[name] = dir
+		}
+	}
+
+	return dirs
+}
+
+// Lookup returns the location of the user directory with the specified name
+// (e.g. DOWNLOAD or PROJECTS), in any letter case. Both well known and custom
+// user directories are looked up. Custom user directories which are not
+// defined are read from the XDG_<NAME>_DIR environment variable, if set to an
+// absolute path. The returned boolean value is false if the user directory is
+// not defined.
+func (d Directories) Lookup(name string) (string, bool) {
+	var dir string
+	switch name = strings.ToUpper(name); name {
+	case "DESKTOP":
+		dir = d.Desktop
+	case "DOWNLOAD":
+		dir = d.Download
+	case "DOCUMENTS":
+		dir = d.Documents
+	case "MUSIC":
+		dir = d.Music
+	case "PICTURES":
+		dir = d.Pictures
+	case "VIDEOS":
+		dir = d.Videos
+	case "TEMPLATES":
+		dir = d.Templates
+	case "PUBLICSHARE":
+		dir = d.PublicShare
+	default:
+		dir = d.Custom()[name]
+		if dir == "" {
+			dir = pathutil.EnvPath(EnvName(name))
+		}
+	}
+
+	return dir, dir != ""
+}
+
+// SyntaxError describes a malformed line of a user directories config file,
+// reported when parsing the file in strict mode.
+type SyntaxError struct {
+	// Line contains the line number, starting from 1.
+	Line int
+
+	// Msg describes the problem.
+	Msg string
+}
+
+// Error returns a textual representation of the syntax error.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("user directories config: line %d: %s", e.Line, e.Msg)
 }
@@ -0,0 +1,113 @@
+package userdirs_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adrg/xdg/internal/userdirs"
+)
+
+func TestLookup(t *testing.T) {
+	dirs := userdirs.Directories{
+		Desktop:     "/home/test/Desktop",
+		Download:    "/home/test/Downloads",
+		Documents:   "/home/test/Documents",
+		Music:       "/home/test/Music",
+		Pictures:    "/home/test/Pictures",
+		Videos:      "/home/test/Videos",
+		Templates:   "/home/test/Templates",
+		PublicShare: "/home/test/Public",
+	}
+	userdirs.SetCustom(&dirs, map[string]string{
+		"PROJECTS": "/home/test/Projects",
+	})
+
+	expected := map[string]string{
+		"DESKTOP":     dirs.Desktop,
+		"download":    dirs.Download,
+		"Documents":   dirs.Documents,
+		"MUSIC":       dirs.Music,
+		"PICTURES":    dirs.Pictures,
+		"VIDEOS":      dirs.Videos,
+		"TEMPLATES":   dirs.Templates,
+		"PUBLICSHARE": dirs.PublicShare,
+		"projects":    "/home/test/Projects",
+	}
+	for name, dir := range expected {
+		actual, ok := dirs.Lookup(name)
+		require.True(t, ok)
+		require.Equal(t, dir, actual)
+	}
+
+	// Test undefined user directories.
+	t.Setenv("XDG_SCREENSHOTS_DIR", "")
+	_, ok := dirs.Lookup("SCREENSHOTS")
+	require.False(t, ok)
+
+	// Test user directories defined by environment variables.
+	t.Setenv("XDG_SCREENSHOTS_DIR", "/srv/screenshots")
+	dir, ok := dirs.Lookup("screenshots")
+	require.True(t, ok)
+	require.Equal(t, "/srv/screenshots", dir)
+
+	t.Setenv("XDG_SCREENSHOTS_DIR", "screenshots")
+	_, ok = dirs.Lookup("SCREENSHOTS")
+	require.False(t, ok)
+
+	_, ok = userdirs.Directories{}.Lookup("DESKTOP")
+	require.False(t, ok)
+}
+
+func TestEnvName(t *testing.T) {
+	require.Equal(t, userdirs.EnvDownloadDir, userdirs.EnvName("DOWNLOAD"))
+	require.Equal(t, "XDG_PROJECTS_DIR", userdirs.EnvName("PROJECTS"))
+}
+
+func TestAll(t *testing.T) {
+	dirs := userdirs.Directories{
+		Download: "/home/test/Downloads",
+		Music:    "/home/test/Music",
+	}
+	userdirs.SetCustom(&dirs, map[string]string{
+		"PROJECTS": "/home/test/Projects",
+		"EMPTY":    "",
+	})
+
+	require.Equal(t, map[string]string{
+		"DOWNLOAD": "/home/test/Downloads",
+		"MUSIC":    "/home/test/Music",
+		"PROJECTS": "/home/test/Projects",
+	}, dirs.All())
+	require.Empty(t, userdirs.Directories{}.All())
+}
+
+func TestCustom(t *testing.T) {
+	custom := map[string]string{"PROJECTS": "/home/test/Projects"}
+
+	var dirs userdirs.Directories
+	userdirs.SetCustom(&dirs, custom)
+	require.Equal(t, custom, dirs.Custom())
+
+	// Test that the returned map is a copy.
+	dirs.Custom()["PROJECTS"] = "/srv/projects"
+	require.Equal(t, custom, dirs.Custom())
+
+	// Test that directories are compared by value.
+	var other userdirs.Directories
+	userdirs.SetCustom(&other, map[string]string{
+		"PROJECTS": "/home/test/Projects",
+		"BOOKS":    "/home/test/Books",
+	})
+	require.False(t, other == dirs)
+
+	userdirs.SetCustom(&dirs, map[string]string{
+		"BOOKS":    "/home/test/Books",
+		"PROJECTS": "/home/test/Projects",
+	})
+	require.True(t, other == dirs)
+
+	userdirs.SetCustom(&dirs, nil)
+	require.Nil(t, dirs.Custom())
+	require.True(t, dirs == userdirs.Directories{})
+}
@@ -0,0 +1,177 @@
+package watch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// inotifyMask defines the inotify events which trigger a check of the
+// watched files.
+const inotifyMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_CLOSE_WRITE |
+	unix.IN_ATTRIB | unix.IN_MOVED_FROM | unix.IN_MOVED_TO |
+	unix.IN_DELETE_SELF | unix.IN_MOVE_SELF | unix.IN_ONLYDIR
+
+// inotifyNotifier signals changes using inotify. The parent directory of each
+// watched file is monitored. If it does not exist, its closest existing
+// ancestor is monitored instead, until the directory is created. Errors
+// encountered while reading the inotify events stop the notifier.
+type inotifyNotifier struct {
+	paths   []string
+	file    *os.File
+	watches map[string]int
+	c       chan struct{}
+	errors  chan error
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newNotifier(paths []string, _ time.Duration) (notifier, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+
+	n := &inotifyNotifier{
+		paths:   paths,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		watches: map[string]int{},
+		c:       make(chan struct{}, 1),
+		errors:  make(chan error),
+		done:    make(chan struct{}),
+	}
+	if err := n.sync(); err != nil {
+		_ = n.file.Close()
+		return nil, err
+	}
+
+	n.wg.Add(1)
+	go n.run()
+
+	return n, nil
+}
+
+func (n *inotifyNotifier) C() <-chan struct{} {
+	return n.c
+}
+
+func (n *inotifyNotifier) Errors() <-chan error {
+	return n.errors
+}
+
+func (n *inotifyNotifier) Close() error {
+	close(n.done)
+	err := n.file.Close()
+	n.wg.Wait()
+
+	return err
+}
+
+func (n *inotifyNotifier) run() {
+	defer n.wg.Done()
+
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		size, err := n.file.Read(buf)
+		if err != nil {
+			// Interrupted reads are retried by the runtime poller, so the
+			// remaining errors are not transient. The notifier is stopped in
+			// order to avoid retrying the read indefinitely.
+			if !errors.Is(err, os.ErrClosed) {
+				n.sendError(err)
+			}
+			return
+		}
+
+		// Discard the watches removed by the kernel.
+		for offset := 0; offset+unix.SizeofInotifyEvent <= size; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			if event.Mask&unix.IN_IGNORED != 0 {
+				for dir, wd := range n.watches {
+					if wd == int(event.Wd) {
+						delete(n.watches, dir)
+					}
+				}
+			}
+
+			offset += unix.SizeofInotifyEvent + int(event.Len)
+		}
+
+		if err := n.sync(); err != nil {
+			n.sendError(err)
+		}
+		signal(n.c)
+	}
+}
+
+// sync updates the set of monitored directories, based on which directories
+// currently exist.
+func (n *inotifyNotifier) sync() error {
+	dirs := map[string]struct{}{}
+	for _, p := range n.paths {
+		dirs[closestDir(filepath.Dir(p))] = struct{}{}
+	}
+
+	// The file descriptor is accessed through the raw connection, as calling
+	// the Fd method of the file would switch it to blocking mode.
+	conn, err := n.file.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	err = conn.Control(func(fd uintptr) {
+		for dir, wd := range n.watches {
+			if _, ok := dirs[dir]; !ok {
+				if _, err := unix.InotifyRmWatch(int(fd), uint32(wd)); err == nil {
+					delete(n.watches, dir)
+				}
+			}
+		}
+		for dir := range dirs {
+			if _, ok := n.watches[dir]; ok {
+				continue
+			}
+
+			wd, err := unix.InotifyAddWatch(int(fd), dir, inotifyMask)
+			if err != nil {
+				// The directory might have been removed in the meantime.
+				if !errors.Is(err, unix.ENOENT) && !errors.Is(err, unix.ENOTDIR) {
+					errs = append(errs, &os.PathError{Op: "inotify_add_watch", Path: dir, Err: err})
+				}
+				continue
+			}
+			n.watches[dir] = wd
+		}
+	})
+
+	return errors.Join(append(errs, err)...)
+}
+
+func (n *inotifyNotifier) sendError(err error) {
+	select {
+	case n.errors <- err:
+	case <-n.done:
+	}
+}
+
+// closestDir returns the specified directory, if it exists, or its closest
+// existing ancestor.
+func closestDir(dir string) string {
+	for {
+		if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,51 @@
+//go:build !linux
+
+package watch
+
+import "time"
+
+// pollNotifier signals changes periodically, on platforms which do not
+// support file system notifications.
+type pollNotifier struct {
+	ticker *time.Ticker
+	c      chan struct{}
+	errors chan error
+	done   chan struct{}
+}
+
+func newNotifier(_ []string, interval time.Duration) (notifier, error) {
+	n := &pollNotifier{
+		ticker: time.NewTicker(interval),
+		c:      make(chan struct{}, 1),
+		errors: make(chan error),
+		done:   make(chan struct{}),
+	}
+	go n.run()
+
+	return n, nil
+}
+
+func (n *pollNotifier) C() <-chan struct{} {
+	return n.c
+}
+
+func (n *pollNotifier) Errors() <-chan error {
+	return n.errors
+}
+
+func (n *pollNotifier) Close() error {
+	n.ticker.Stop()
+	close(n.done)
+	return nil
+}
+
+func (n *pollNotifier) run() {
+	for {
+		select {
+		case <-n.ticker.C:
+			signal(n.c)
+		case <-n.done:
+			return
+		}
+	}
+}
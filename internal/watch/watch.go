@@ -0,0 +1,210 @@
+package watch
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// Op describes a change of a watched file.
+type Op int
+
+// Watched file changes.
+const (
+	Create Op = iota + 1
+	Modify
+	Remove
+)
+
+// String returns a textual representation of the operation.
+func (op Op) String() string {
+	switch op {
+	case Create:
+		return "CREATE"
+	case Modify:
+		return "MODIFY"
+	case Remove:
+		return "REMOVE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Event describes a change of a watched file.
+type Event struct {
+	// Path contains the location of the changed file.
+	Path string
+
+	// Op describes the change of the file.
+	Op Op
+}
+
+// notifier signals that the watched files might have changed.
+type notifier interface {
+	// C returns the channel on which change notifications are delivered.
+	C() <-chan struct{}
+
+	// Errors returns the channel on which notification errors are delivered.
+	Errors() <-chan error
+
+	// Close stops the delivery of notifications.
+	Close() error
+}
+
+// Watcher reports the creation, modification and removal of a set of files.
+// The files, as well as their parent directories, are not required to exist.
+type Watcher struct {
+	paths    []string
+	states   map[string]os.FileInfo
+	statesMu sync.RWMutex
+
+	notifier notifier
+	events   chan Event
+	errors   chan error
+	done     chan struct{}
+	wg       sync.WaitGroup
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// New returns a watcher for the files at the specified locations.
+// The interval parameter is used on platforms which do not support file
+// system notifications, as the period at which the files are checked.
+func New(paths []string, interval time.Duration) (*Watcher, error) {
+	n, err := newNotifier(paths, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		paths:    paths,
+		states:   make(map[string]os.FileInfo, len(paths)),
+		notifier: n,
+		events:   make(chan Event),
+		errors:   make(chan error),
+		done:     make(chan struct{}),
+	}
+	for _, p := range paths {
+		w.states[p] = stat(p)
+	}
+
+	w.wg.Add(1)
+	go w.run()
+
+	return w, nil
+}
+
+// Events returns the channel on which file changes are delivered.
+// The channel is closed when the watcher is closed.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Errors returns the channel on which watch errors are delivered. Errors must
+// be received from the channel in order for the watcher to make progress.
+// The channel is closed when the watcher is closed.
+func (w *Watcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Exists returns true if the file at the specified location existed when the
+// watcher last checked it.
+func (w *Watcher) Exists(path string) bool {
+	w.statesMu.RLock()
+	defer w.statesMu.RUnlock()
+
+	return w.states[path] != nil
+}
+
+// Close stops the watcher and closes its channels.
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		w.closeErr = w.notifier.Close()
+		w.wg.Wait()
+
+		close(w.events)
+		close(w.errors)
+	})
+
+	return w.closeErr
+}
+
+func (w *Watcher) run() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case err := <-w.notifier.Errors():
+			select {
+			case w.errors <- err:
+			case <-w.done:
+				return
+			}
+		case <-w.notifier.C():
+			if !w.check() {
+				return
+			}
+		}
+	}
+}
+
+// check compares the current state of the watched files with the previously
+// recorded one, and delivers events for the files which changed. It returns
+// false if the watcher was closed while delivering events.
+func (w *Watcher) check() bool {
+	for _, p := range w.paths {
+		w.statesMu.Lock()
+		prev, curr := w.states[p], stat(p)
+		w.states[p] = curr
+		w.statesMu.Unlock()
+
+		var op Op
+		switch {
+		case prev == nil && curr != nil:
+			op = Create
+		case prev != nil && curr == nil:
+			op = Remove
+		case prev != nil && curr != nil && changed(prev, curr):
+			op = Modify
+		default:
+			continue
+		}
+
+		select {
+		case w.events <- Event{Path: p, Op: op}:
+		case <-w.done:
+			return false
+		}
+	}
+
+	return true
+}
+
+func stat(path string) os.FileInfo {
+	fi, err := os.Stat(path)
+	if err != nil || fi.IsDir() {
+		return nil
+	}
+
+	return fi
+}
+
+func changed(prev, curr os.FileInfo) bool {
+	return !os.SameFile(prev, curr) ||
+		!prev.ModTime().Equal(curr.ModTime()) ||
+		prev.Size() != curr.Size() ||
+		prev.Mode() != curr.Mode()
+}
+
+// signal delivers a change notification on the specified channel, unless
+// a notification is already pending.
+func signal(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
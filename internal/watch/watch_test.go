@@ -0,0 +1,69 @@
+package watch_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adrg/xdg/internal/watch"
+)
+
+func receiveEvent(t *testing.T, w *watch.Watcher) watch.Event {
+	select {
+	case event := <-w.Events():
+		return event
+	case err := <-w.Errors():
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for event")
+	}
+
+	return watch.Event{}
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "appname", "nested", "app.yaml")
+
+	w, err := watch.New([]string{path}, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.False(t, w.Exists(path))
+
+	// Test file creation in non-existent directories.
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte("a: 1"), 0o600))
+	require.Equal(t, watch.Event{Path: path, Op: watch.Create}, receiveEvent(t, w))
+	require.True(t, w.Exists(path))
+
+	// Test file modification.
+	require.NoError(t, os.WriteFile(path, []byte("a: 12"), 0o600))
+	require.Equal(t, watch.Event{Path: path, Op: watch.Modify}, receiveEvent(t, w))
+
+	// Test file replacement.
+	tmpPath := filepath.Join(dir, "app.yaml.tmp")
+	require.NoError(t, os.WriteFile(tmpPath, []byte("a: 2"), 0o600))
+	require.NoError(t, os.Rename(tmpPath, path))
+	require.Equal(t, watch.Event{Path: path, Op: watch.Modify}, receiveEvent(t, w))
+
+	// Test file removal.
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "appname")))
+	require.Equal(t, watch.Event{Path: path, Op: watch.Remove}, receiveEvent(t, w))
+	require.False(t, w.Exists(path))
+
+	// Test closing the watcher.
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+
+	_, ok := <-w.Events()
+	require.False(t, ok)
+}
+
+func TestOpString(t *testing.T) {
+	require.Equal(t, "CREATE", watch.Create.String())
+	require.Equal(t, "MODIFY", watch.Modify.String())
+	require.Equal(t, "REMOVE", watch.Remove.String())
+	require.Equal(t, "UNKNOWN", watch.Op(0).String())
+}
@@ -0,0 +1,32 @@
+package xdg
+
+import (
+	"io"
+
+	"github.com/adrg/xdg/internal/logfile"
+)
+
+// LogOptions defines the rotation settings of log files opened using LogFile.
+type LogOptions = logfile.Options
+
+// defaultLogOptions defines the rotation settings used by LogFile if no
+// options are provided.
+var defaultLogOptions = LogOptions{
+	MaxSize:    10 << 20,
+	MaxBackups: 5,
+}
+
+// LogFile opens the specified log file for appending, in a suitable location
+// relative to the state directory. The relPath parameter must contain the name
+// of the log file, and optionally, a set of parent directories
+// (e.g. appname/app.log). If the specified directories do not exist, they will
+// be created relative to the base state directory.
+// The log file is rotated according to the provided options. Rotated files are
+// stored next to the log file and are named after it, using a timestamp suffix
+// (e.g. app-2006-01-02T15-04-05.000.log). If opts is nil, log files are rotated
+// when they exceed 10 MiB and the last 5 rotated files are retained.
+// The returned writer is safe for concurrent use and can be used as the output
+// of the standard library loggers (e.g. log/slog handlers).
+func LogFile(relPath string, opts *LogOptions) (io.WriteCloser, error) {
+	return baseDirs.logFile(relPath, opts)
+}
@@ -3,17 +3,20 @@
 package xdg
 
 import (
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 
+	"github.com/adrg/xdg/internal/locale"
 	"github.com/adrg/xdg/internal/pathutil"
 	"github.com/adrg/xdg/internal/userdirs"
 )
 
 func initDirs(home string) {
 	initBaseDirs(home)
-	initUserDirs(home, baseDirs.configHome)
+	UserDirs = loadUserDirs(home, baseDirs.configHome, baseDirs.config)
 }
 
 func initBaseDirs(home string) {
@@ -53,18 +56,97 @@ func initBaseDirs(home string) {
 	baseDirs.fonts = pathutil.Unique(fontDirs)
 }
 
-func initUserDirs(home, configHome string) {
-	dirs, err := userdirs.ParseConfigFile(filepath.Join(configHome, "user-dirs.dirs"))
+// loadUserDirs returns the locations of the user directories, read from the
+// user directories config file and from the environment.
+func loadUserDirs(home, configHome string, configDirs []string) UserDirectories {
+	dirs, err := userdirs.ParseConfigFile(filepath.Join(configHome, userdirs.ConfigFileName))
 	if err != nil {
 		dirs = &UserDirectories{}
 	}
 
-	UserDirs.Desktop = pathutil.EnvPath(userdirs.EnvDesktopDir, dirs.Desktop, filepath.Join(home, "Desktop"))
-	UserDirs.Download = pathutil.EnvPath(userdirs.EnvDownloadDir, dirs.Download, filepath.Join(home, "Downloads"))
-	UserDirs.Documents = pathutil.EnvPath(userdirs.EnvDocumentsDir, dirs.Documents, filepath.Join(home, "Documents"))
-	UserDirs.Music = pathutil.EnvPath(userdirs.EnvMusicDir, dirs.Music, filepath.Join(home, "Music"))
-	UserDirs.Pictures = pathutil.EnvPath(userdirs.EnvPicturesDir, dirs.Pictures, filepath.Join(home, "Pictures"))
-	UserDirs.Videos = pathutil.EnvPath(userdirs.EnvVideosDir, dirs.Videos, filepath.Join(home, "Videos"))
-	UserDirs.Templates = pathutil.EnvPath(userdirs.EnvTemplatesDir, dirs.Templates, filepath.Join(home, "Templates"))
-	UserDirs.PublicShare = pathutil.EnvPath(userdirs.EnvPublicShareDir, dirs.PublicShare, filepath.Join(home, "Public"))
+	defaults := userDirDefaults(configDirs)
+	lang := userDirsLocale(configHome)
+	defaultPath := func(name string) string {
+		dir := defaults[name]
+		if filepath.IsAbs(dir) {
+			return dir
+		}
+
+		// Use the localized default location, unless only the untranslated
+		// one exists (e.g. the locale changed after the directory was created).
+		localized := filepath.Join(home, userdirs.Translate(dir, lang))
+		if dir = filepath.Join(home, dir); localized != dir && !pathutil.Exists(localized) && pathutil.Exists(dir) {
+			return dir
+		}
+		return localized
+	}
+
+	userDirs := UserDirectories{
+		Desktop:     pathutil.EnvPath(userdirs.EnvDesktopDir, dirs.Desktop, defaultPath("DESKTOP")),
+		Download:    pathutil.EnvPath(userdirs.EnvDownloadDir, dirs.Download, defaultPath("DOWNLOAD")),
+		Documents:   pathutil.EnvPath(userdirs.EnvDocumentsDir, dirs.Documents, defaultPath("DOCUMENTS")),
+		Music:       pathutil.EnvPath(userdirs.EnvMusicDir, dirs.Music, defaultPath("MUSIC")),
+		Pictures:    pathutil.EnvPath(userdirs.EnvPicturesDir, dirs.Pictures, defaultPath("PICTURES")),
+		Videos:      pathutil.EnvPath(userdirs.EnvVideosDir, dirs.Videos, defaultPath("VIDEOS")),
+		Templates:   pathutil.EnvPath(userdirs.EnvTemplatesDir, dirs.Templates, defaultPath("TEMPLATES")),
+		PublicShare: pathutil.EnvPath(userdirs.EnvPublicShareDir, dirs.PublicShare, defaultPath("PUBLICSHARE")),
+	}
+
+	// Initialize custom user directories, defined either in the config file
+	// or in the defaults file.
+	custom := map[string]string{}
+	for name := range defaults {
+		if _, ok := userdirs.DefaultNames[name]; !ok {
+			custom[name] = pathutil.EnvPath(userdirs.EnvName(name), defaultPath(name))
+		}
+	}
+	for name, dir := range dirs.Custom() {
+		custom[name] = pathutil.EnvPath(userdirs.EnvName(name), dir)
+	}
+	userdirs.SetCustom(&userDirs, custom)
+
+	return userDirs
+}
+
+// userDirDefaults returns the default locations of the user directories,
+// relative to the home directory. The built-in defaults are overridden by
+// the first user-dirs.defaults file found in the config directories. The
+// user-dirs.conf settings files are not taken into account, as they only
+// disable the management of the user directories (see userDirsManaged).
+func userDirDefaults(configDirs []string) map[string]string {
+	defaults := maps.Clone(userdirs.DefaultNames)
+
+	if name, err := pathutil.Search(userdirs.DefaultsFileName, configDirs); err == nil {
+		if fileDefaults, err := userdirs.ParseDefaultsFile(name); err == nil {
+			maps.Copy(defaults, fileDefaults)
+		}
+	}
+
+	return defaults
+}
+
+// userDirsEnabled returns true if the management of the user directories is
+// not disabled by the user-dirs.conf settings files.
+func userDirsEnabled(configHome string, configDirs []string) bool {
+	// The settings in the config home directory take precedence over the ones
+	// in the system config directories.
+	settingsFiles := []string{filepath.Join(configHome, userdirs.SettingsFileName)}
+	for _, dir := range configDirs {
+		settingsFiles = append(settingsFiles, filepath.Join(dir, userdirs.SettingsFileName))
+	}
+	slices.Reverse(settingsFiles)
+
+	settings, err := userdirs.ParseSettingsFiles(settingsFiles...)
+	return err == nil && settings.Enabled
+}
+
+// userDirsLocale returns the locale used for translating the default locations
+// of the user directories. The locale recorded by xdg-user-dirs-update in the
+// user-dirs.locale file takes precedence over the locale of the environment.
+func userDirsLocale(configHome string) locale.Locale {
+	if l, err := userdirs.ParseLocaleFile(filepath.Join(configHome, userdirs.LocaleFileName)); err == nil && !l.IsZero() {
+		return l
+	}
+
+	return locale.Messages()
 }
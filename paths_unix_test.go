@@ -6,7 +6,9 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -143,6 +145,11 @@ func TestCustomBaseDirs(t *testing.T) {
 func TestDefaultUserDirs(t *testing.T) {
 	home := xdg.Home
 
+	// Use untranslated default locations, regardless of the host locale.
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_DIRS", t.TempDir())
+	t.Setenv("LC_ALL", "C")
+
 	testDirs(t,
 		&envSample{
 			name:     "XDG_DESKTOP_DIR",
@@ -254,3 +261,320 @@ func TestHomeNotSet(t *testing.T) {
 	xdg.Reload()
 	require.Equal(t, envHomeVal, xdg.Home)
 }
+
+func TestWatchUserDirs(t *testing.T) {
+	var (
+		configHome = t.TempDir()
+		configDir  = t.TempDir()
+	)
+
+	t.Cleanup(xdg.Reload)
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_CONFIG_DIRS", configDir)
+	t.Setenv("XDG_DOWNLOAD_DIR", "")
+	t.Setenv("LC_ALL", "C")
+	xdg.Reload()
+	require.Equal(t, filepath.Join(xdg.Home, "Downloads"), xdg.UserDirs.Download)
+
+	w, err := xdg.WatchUserDirs()
+	require.NoError(t, err)
+
+	// UserDirs is updated before the subscribers are notified, so it is
+	// read on the goroutine of the watcher.
+	updates := make(chan xdg.UserDirectories, 1)
+	unsubscribe := w.Subscribe(func(dirs xdg.UserDirectories) {
+		if xdg.UserDirs == dirs {
+			updates <- dirs
+		}
+	})
+
+	// Changes might be reported more than once, so the updates are received
+	// until the expected one is encountered.
+	waitDownloadDir := func(expected string) {
+		for {
+			select {
+			case dirs := <-updates:
+				if dirs.Download == expected {
+					return
+				}
+			case err := <-w.Errors():
+				require.NoError(t, err)
+			case <-time.After(10 * time.Second):
+				require.FailNow(t, "timed out waiting for update")
+			}
+		}
+	}
+
+	// Test creation of the user directories config file.
+	configPath := filepath.Join(configHome, "user-dirs.dirs")
+	require.NoError(t, os.WriteFile(configPath, []byte(`XDG_DOWNLOAD_DIR="/tmp/downloads"`), 0o600))
+	waitDownloadDir("/tmp/downloads")
+	require.Equal(t, "/tmp/downloads", w.UserDirs().Download)
+
+	// Test removal of the user directories config file.
+	require.NoError(t, os.Remove(configPath))
+	waitDownloadDir(filepath.Join(xdg.Home, "Downloads"))
+
+	// Test creation of the user directories defaults file.
+	defaultsPath := filepath.Join(configDir, "user-dirs.defaults")
+	require.NoError(t, os.WriteFile(defaultsPath, []byte("DOWNLOAD=Incoming\n"), 0o600))
+	waitDownloadDir(filepath.Join(xdg.Home, "Incoming"))
+
+	// Test unsubscribing and closing the watcher. Updates are drained, as
+	// subscribers might still be running until the watcher is closed.
+	unsubscribe()
+	go func() {
+		for range updates {
+		}
+	}()
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+	close(updates)
+	require.Equal(t, filepath.Join(xdg.Home, "Incoming"), xdg.UserDirs.Download)
+}
+
+func TestSetUserDirs(t *testing.T) {
+	var (
+		configHome  = t.TempDir()
+		downloadDir = filepath.Join(t.TempDir(), "downloads")
+		projectsDir = filepath.Join(t.TempDir(), "projects")
+	)
+
+	t.Cleanup(xdg.Reload)
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_DOWNLOAD_DIR", "")
+	xdg.Reload()
+
+	require.NoError(t, xdg.SetUserDirs(map[string]string{
+		"DOWNLOAD": downloadDir,
+		"projects": projectsDir,
+	}))
+	require.Equal(t, downloadDir, xdg.UserDirs.Download)
+	require.DirExists(t, downloadDir)
+	require.DirExists(t, projectsDir)
+
+	data, err := os.ReadFile(filepath.Join(configHome, "user-dirs.dirs"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), "XDG_PROJECTS_DIR=")
+
+	// Test invalid user directories.
+	require.Error(t, xdg.SetUserDirs(map[string]string{"DOWN LOAD": downloadDir}))
+	require.Error(t, xdg.SetUserDirs(map[string]string{"DOWNLOAD": "downloads"}))
+}
+
+func TestUserDirDefaults(t *testing.T) {
+	var (
+		home       = xdg.Home
+		configHome = t.TempDir()
+		configDirs = []string{t.TempDir(), t.TempDir()}
+	)
+
+	t.Cleanup(xdg.Reload)
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_CONFIG_DIRS", strings.Join(configDirs, string(filepath.ListSeparator)))
+	t.Setenv("XDG_DOWNLOAD_DIR", "")
+	t.Setenv("XDG_MUSIC_DIR", "")
+	t.Setenv("XDG_VIDEOS_DIR", "")
+	t.Setenv("LC_ALL", "C")
+
+	// Test defaults file precedence.
+	require.NoError(t, os.WriteFile(filepath.Join(configDirs[0], "user-dirs.defaults"),
+		[]byte("DOWNLOAD=Incoming\nMUSIC=Media/Music\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(configDirs[1], "user-dirs.defaults"),
+		[]byte("VIDEOS=Media/Videos\n"), 0o600))
+	xdg.Reload()
+
+	require.Equal(t, filepath.Join(home, "Incoming"), xdg.UserDirs.Download)
+	require.Equal(t, filepath.Join(home, "Media", "Music"), xdg.UserDirs.Music)
+	require.Equal(t, filepath.Join(home, "Videos"), xdg.UserDirs.Videos)
+
+	// Test user directories config file precedence.
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "user-dirs.dirs"),
+		[]byte(`XDG_DOWNLOAD_DIR="$HOME/Downloads"`), 0o600))
+	xdg.Reload()
+
+	require.Equal(t, filepath.Join(home, "Downloads"), xdg.UserDirs.Download)
+	require.Equal(t, filepath.Join(home, "Media", "Music"), xdg.UserDirs.Music)
+
+	// Test disabled user directories management, which does not affect the
+	// defaults.
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "user-dirs.conf"),
+		[]byte("enabled=False\n"), 0o600))
+	xdg.Reload()
+	require.Equal(t, filepath.Join(home, "Media", "Music"), xdg.UserDirs.Music)
+}
+
+func TestLocalizedUserDirs(t *testing.T) {
+	var (
+		home       = t.TempDir()
+		configHome = filepath.Join(home, ".config")
+	)
+
+	t.Cleanup(xdg.Reload)
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_CONFIG_DIRS", t.TempDir())
+	t.Setenv("XDG_DOWNLOAD_DIR", "")
+	t.Setenv("XDG_PICTURES_DIR", "")
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "fr_FR.UTF-8")
+
+	// Test environment locale.
+	xdg.Reload()
+	require.Equal(t, filepath.Join(home, "Téléchargements"), xdg.UserDirs.Download)
+	require.Equal(t, filepath.Join(home, "Images"), xdg.UserDirs.Pictures)
+
+	// Test existing untranslated directories.
+	require.NoError(t, os.Mkdir(filepath.Join(home, "Downloads"), 0o700))
+	xdg.Reload()
+	require.Equal(t, filepath.Join(home, "Downloads"), xdg.UserDirs.Download)
+
+	require.NoError(t, os.Mkdir(filepath.Join(home, "Téléchargements"), 0o700))
+	xdg.Reload()
+	require.Equal(t, filepath.Join(home, "Téléchargements"), xdg.UserDirs.Download)
+
+	// Test recorded locale.
+	require.NoError(t, os.MkdirAll(configHome, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "user-dirs.locale"), []byte("de_DE"), 0o600))
+	xdg.Reload()
+	require.Equal(t, filepath.Join(home, "Bilder"), xdg.UserDirs.Pictures)
+}
+
+func TestParseUserDirs(t *testing.T) {
+	config := `XDG_DOWNLOAD_DIR="/home/test/Downloads"
+XDG_MUSIC_DIR="~/Music"
+`
+
+	// Test lenient mode.
+	dirs, err := xdg.ParseUserDirs(strings.NewReader(config))
+	require.NoError(t, err)
+	require.Equal(t, "/home/test/Downloads", dirs.Download)
+	require.Equal(t, filepath.Join(xdg.Home, "Music"), dirs.Music)
+
+	// Test strict mode.
+	_, err = xdg.ParseUserDirsStrict(strings.NewReader(config))
+	require.Error(t, err)
+
+	var syntaxErr *xdg.UserDirsSyntaxError
+	require.ErrorAs(t, err, &syntaxErr)
+	require.Equal(t, 2, syntaxErr.Line)
+}
+
+func TestLookupUserDirs(t *testing.T) {
+	var (
+		configHome = t.TempDir()
+		configDir  = t.TempDir()
+	)
+
+	t.Cleanup(xdg.Reload)
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_CONFIG_DIRS", configDir)
+	t.Setenv("XDG_DOWNLOAD_DIR", "")
+	t.Setenv("XDG_PROJECTS_DIR", "")
+	t.Setenv("XDG_SCREENSHOTS_DIR", "")
+	t.Setenv("LC_ALL", "C")
+
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "user-dirs.dirs"), []byte(`
+XDG_DOWNLOAD_DIR="/home/test/Downloads"
+XDG_PROJECTS_DIR="/home/test/Projects"
+`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "user-dirs.defaults"), []byte(`
+SCREENSHOTS=Pictures/Screenshots
+`), 0o600))
+	xdg.Reload()
+
+	// Test user directories defined in config files.
+	dir, ok := xdg.UserDirs.Lookup("DOWNLOAD")
+	require.True(t, ok)
+	require.Equal(t, "/home/test/Downloads", dir)
+
+	dir, ok = xdg.UserDirs.Lookup("projects")
+	require.True(t, ok)
+	require.Equal(t, "/home/test/Projects", dir)
+
+	dir, ok = xdg.UserDirs.Lookup("SCREENSHOTS")
+	require.True(t, ok)
+	require.Equal(t, filepath.Join(xdg.Home, "Pictures", "Screenshots"), dir)
+
+	_, ok = xdg.UserDirs.Lookup("MISSING")
+	require.False(t, ok)
+
+	// Test environment overrides.
+	t.Setenv("XDG_PROJECTS_DIR", "/srv/projects")
+	t.Setenv("XDG_SCREENSHOTS_DIR", "/srv/screenshots")
+	xdg.Reload()
+
+	dir, _ = xdg.UserDirs.Lookup("PROJECTS")
+	require.Equal(t, "/srv/projects", dir)
+
+	dir, _ = xdg.UserDirs.Lookup("SCREENSHOTS")
+	require.Equal(t, "/srv/screenshots", dir)
+
+	// Test user directories defined only in the environment.
+	t.Setenv("XDG_GAMES_DIR", "/srv/games")
+	xdg.Reload()
+
+	dir, ok = xdg.UserDirs.Lookup("GAMES")
+	require.True(t, ok)
+	require.Equal(t, "/srv/games", dir)
+}
+
+func TestEnsureUserDirs(t *testing.T) {
+	var (
+		home       = t.TempDir()
+		configHome = filepath.Join(home, ".config")
+		configDir  = t.TempDir()
+	)
+
+	t.Cleanup(xdg.Reload)
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_CONFIG_DIRS", configDir)
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	for _, name := range []string{"DESKTOP", "DOWNLOAD", "DOCUMENTS", "MUSIC", "PICTURES", "VIDEOS", "TEMPLATES", "PUBLICSHARE", "PROJECTS"} {
+		t.Setenv("XDG_"+name+"_DIR", "")
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "user-dirs.defaults"),
+		[]byte("DESKTOP=Desktop\nDOWNLOAD=Downloads\nPROJECTS=Projects\n"), 0o600))
+	xdg.Reload()
+
+	// Test first provisioning.
+	require.NoError(t, xdg.EnsureUserDirs())
+	for _, name := range []string{"Schreibtisch", "Downloads", "Dokumente", "Projects"} {
+		require.DirExists(t, filepath.Join(home, name))
+	}
+
+	dirs, err := os.ReadFile(filepath.Join(configHome, "user-dirs.dirs"))
+	require.NoError(t, err)
+	require.Contains(t, string(dirs), `XDG_DESKTOP_DIR="$HOME/Schreibtisch"`)
+	require.Contains(t, string(dirs), `XDG_PROJECTS_DIR="$HOME/Projects"`)
+
+	userLocale, err := os.ReadFile(filepath.Join(configHome, "user-dirs.locale"))
+	require.NoError(t, err)
+	require.Equal(t, "de_DE\n", string(userLocale))
+
+	// Test existing config file.
+	require.NoError(t, os.RemoveAll(filepath.Join(home, "Projects")))
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "user-dirs.dirs"),
+		[]byte(`XDG_DESKTOP_DIR="$HOME"`), 0o600))
+	xdg.Reload()
+
+	require.NoError(t, xdg.EnsureUserDirs())
+	require.DirExists(t, filepath.Join(home, "Projects"))
+
+	dirs, err = os.ReadFile(filepath.Join(configHome, "user-dirs.dirs"))
+	require.NoError(t, err)
+	require.Equal(t, `XDG_DESKTOP_DIR="$HOME"`, string(dirs))
+
+	// Test disabled user directories management.
+	require.NoError(t, os.Remove(filepath.Join(configHome, "user-dirs.dirs")))
+	require.NoError(t, os.RemoveAll(filepath.Join(home, "Dokumente")))
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "user-dirs.conf"),
+		[]byte("enabled=False\n"), 0o600))
+	xdg.Reload()
+
+	require.NoError(t, xdg.EnsureUserDirs())
+	require.NoFileExists(t, filepath.Join(configHome, "user-dirs.dirs"))
+	require.NoDirExists(t, filepath.Join(home, "Dokumente"))
+}
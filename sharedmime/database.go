@@ -0,0 +1,325 @@
+package sharedmime
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/adrg/xdg"
+)
+
+// Database represents a shared MIME-info database, loaded from the mime
+// subdirectories of the XDG data directories.
+type Database struct {
+	typeInfo
+
+	dirs  []string
+	globs []Glob
+	magic []*magicSection
+
+	// magicExtent contains the number of bytes required to evaluate all the
+	// magic rules of the database.
+	magicExtent int
+}
+
+// Dirs returns the locations of the shared MIME-info databases, in order of
+// precedence: the mime directory inside xdg.DataHome, followed by the mime
+// directories inside xdg.DataDirs.
+func Dirs() []string {
+	dirs := []string{filepath.Join(xdg.DataHome, "mime")}
+	for _, dir := range xdg.DataDirs {
+		dirs = append(dirs, filepath.Join(dir, "mime"))
+	}
+
+	return dirs
+}
+
+// Load loads the shared MIME-info database from the directories returned
+// by Dirs. See LoadDirs for more details.
+func Load() (*Database, error) {
+	return LoadDirs(Dirs()...)
+}
+
+// LoadDirs loads the shared MIME-info database from the specified mime
+// directories, which must be provided in order of precedence. Missing
+// directories and files are ignored.
+//
+// The patterns used to match file names are read from the globs2 file of
+// each directory or, if it does not exist, from the legacy globs file.
+// The special __NOGLOBS__ pattern of a MIME type discards the patterns of
+// that MIME type defined in directories with lower precedence.
+//
+// The rules used to match the content of files are read from the magic
+// file of each directory. Similarly, the special __NOMAGIC__ rule of a MIME
+// type discards the rules of that MIME type defined in directories with
+// lower precedence.
+//
+// The aliases, subclasses, icons and generic-icons files of the directories
+// are also loaded, in order to provide the hierarchy and presentation data
+// of the MIME types. The data defined in directories with higher precedence
+// overrides the data defined in directories with lower precedence.
+func LoadDirs(dirs ...string) (*Database, error) {
+	db := &Database{
+		dirs: slices.Clone(dirs),
+		typeInfo: typeInfo{
+			names:        map[string]string{},
+			aliases:      map[string]string{},
+			parents:      map[string][]string{},
+			icons:        map[string]string{},
+			genericIcons: map[string]string{},
+		},
+	}
+
+	// Load directories in reverse order of precedence, so that the patterns
+	// defined in directories with lower precedence can be discarded.
+	for _, dir := range slices.Backward(dirs) {
+		globs, err := loadGlobs(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, glob := range globs {
+			if glob.Pattern != noGlobs {
+				continue
+			}
+			db.globs = slices.DeleteFunc(db.globs, func(g Glob) bool {
+				return g.MimeType == glob.MimeType
+			})
+		}
+		globs = slices.DeleteFunc(globs, func(g Glob) bool {
+			return g.Pattern == noGlobs
+		})
+
+		db.globs = append(globs, db.globs...)
+		for _, glob := range globs {
+			db.addName(glob.MimeType)
+		}
+
+		magic, err := loadMagic(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, section := range magic {
+			if !section.noMagic {
+				continue
+			}
+			db.magic = slices.DeleteFunc(db.magic, func(s *magicSection) bool {
+				return s.mimeType == section.mimeType
+			})
+		}
+		db.magic = append(magic, db.magic...)
+		for _, section := range magic {
+			db.addName(section.mimeType)
+		}
+
+		if err := db.typeInfo.load(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	// Sort magic sections by priority. The order of sections with the same
+	// priority is preserved, so that the ones defined in directories with
+	// higher precedence are checked first.
+	slices.SortStableFunc(db.magic, func(a, b *magicSection) int {
+		return b.priority - a.priority
+	})
+	for _, section := range db.magic {
+		for _, rule := range section.rules {
+			db.magicExtent = max(db.magicExtent, rule.extent())
+		}
+	}
+
+	return db, nil
+}
+
+func loadGlobs(dir string) ([]Glob, error) {
+	legacy := false
+	f, err := os.Open(filepath.Join(dir, "globs2"))
+	if errors.Is(err, os.ErrNotExist) {
+		legacy = true
+		f, err = os.Open(filepath.Join(dir, "globs"))
+	}
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	return parseGlobs(f, legacy)
+}
+
+func loadMagic(dir string) ([]*magicSection, error) {
+	name := filepath.Join(dir, "magic")
+	data, err := os.ReadFile(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	sections, err := parseMagic(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return sections, nil
+}
+
+// Globs returns the file name patterns of the database, in order of
+// precedence.
+func (db *Database) Globs() []Glob {
+	return slices.Clone(db.globs)
+}
+
+// TypesByFilename returns the MIME types matching the specified file name,
+// based on the file name patterns of the database. Only the base name of
+// the specified path is matched. If multiple patterns match the file name,
+// literal patterns (e.g. Makefile) are preferred over suffix patterns
+// (e.g. *.png), which are preferred over other patterns (e.g. README*).
+// Out of the matching patterns of the same kind, the ones with the highest
+// weight are chosen and, for equal weights, the longest patterns win.
+// Multiple MIME types are returned if the best matching patterns are
+// associated with different MIME types.
+func (db *Database) TypesByFilename(name string) []string {
+	var types []string
+	for _, glob := range matchGlobs(db.globs, name) {
+		if !slices.Contains(types, glob.MimeType) {
+			types = append(types, glob.MimeType)
+		}
+	}
+
+	return types
+}
+
+// TypeByFilename returns the MIME type matching the specified file name.
+// If multiple MIME types match, the one defined with the highest precedence
+// is returned. An empty string is returned if no MIME type matches.
+// See Database.TypesByFilename for more details.
+func (db *Database) TypeByFilename(name string) string {
+	if types := db.TypesByFilename(name); len(types) > 0 {
+		return types[0]
+	}
+
+	return ""
+}
+
+// TypeByContent returns the MIME type of the content contained in the
+// provided reader, based on the magic rules of the database. Only the bytes
+// required to evaluate the magic rules are read. If multiple rules match the
+// content, the MIME type of the rule with the highest priority is returned.
+// If no rules match, text/plain is returned for textual content and
+// application/octet-stream for binary content.
+func (db *Database) TypeByContent(r io.Reader) (string, error) {
+	data, err := db.readHeader(r)
+	if err != nil {
+		return "", err
+	}
+
+	if mimeType := db.matchMagic(data); mimeType != "" {
+		return mimeType, nil
+	}
+	return fallbackType(data), nil
+}
+
+// TypeByReader returns the MIME type of the file with the specified name,
+// whose content is contained in the provided reader, as recommended by the
+// Shared MIME-info Database specification. The file name is matched first.
+// If all the best matching patterns are associated with the same MIME type,
+// that MIME type is returned without reading the content. Otherwise, the
+// content is matched against the magic rules of the database. If any of the
+// MIME types matching the file name is equal to or is a subclass of the MIME
+// type matching the content, that MIME type is returned. Otherwise, the
+// MIME type matching the content is returned. The file name can be empty
+// and the reader can be nil, in which case only the file name is matched.
+func (db *Database) TypeByReader(name string, r io.Reader) (string, error) {
+	var types []string
+	if name != "" {
+		types = db.TypesByFilename(name)
+	}
+	if len(types) == 1 {
+		return types[0], nil
+	}
+	if r == nil {
+		if len(types) > 0 {
+			return types[0], nil
+		}
+		return "application/octet-stream", nil
+	}
+
+	data, err := db.readHeader(r)
+	if err != nil {
+		return "", err
+	}
+
+	mimeType := db.matchMagic(data)
+	if mimeType != "" {
+		for _, t := range types {
+			if db.IsSubclassOf(t, mimeType) {
+				return t, nil
+			}
+		}
+
+		return mimeType, nil
+	}
+	if len(types) > 0 {
+		return types[0], nil
+	}
+
+	return fallbackType(data), nil
+}
+
+// TypeByFile returns the MIME type of the file at the specified location.
+// Directories have the inode/directory MIME type. See Database.TypeByReader
+// for more details.
+func (db *Database) TypeByFile(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if fi.IsDir() {
+		return "inode/directory", nil
+	}
+
+	return db.TypeByReader(name, f)
+}
+
+// readHeader reads the bytes required to evaluate the magic rules of the
+// database from the provided reader.
+func (db *Database) readHeader(r io.Reader) ([]byte, error) {
+	data := make([]byte, max(db.magicExtent, textCheckLen))
+	n, err := io.ReadFull(r, data)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+
+	return data[:n], nil
+}
+
+// matchMagic returns the MIME type of the magic section with the highest
+// priority matching the provided data.
+func (db *Database) matchMagic(data []byte) string {
+	for _, section := range db.magic {
+		if section.match(data) {
+			return section.mimeType
+		}
+	}
+
+	return ""
+}
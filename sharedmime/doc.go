@@ -0,0 +1,55 @@
+/*
+Package sharedmime provides an implementation of the Shared MIME-info Database
+specification. The database, installed in the mime subdirectories of the XDG
+data directories, is used by desktop environments in order to determine the
+MIME types of files.
+
+	For more information regarding the Shared MIME-info Database specification see:
+	https://specifications.freedesktop.org/shared-mime-info-spec/latest/
+
+# Usage
+
+	package main
+
+	import (
+		"fmt"
+		"log"
+
+		"github.com/adrg/xdg/sharedmime"
+	)
+
+	func main() {
+		db, err := sharedmime.Load()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Determine the MIME type of a file based on its name.
+		fmt.Println(db.TypeByFilename("photo.PNG"))
+
+		// Determine the MIME type of a file based on its name and content.
+		mimeType, err := db.TypeByFile("/usr/bin/env")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(mimeType)
+
+		// Retrieve the description and the icon of the MIME type.
+		if comment, err := db.Comment(mimeType); err == nil {
+			fmt.Println(comment)
+		}
+		fmt.Println(db.Icon(mimeType))
+
+		// Register a custom MIME type in the user's database.
+		err = sharedmime.Register("myapp", sharedmime.TypeDefinition{
+			Type:    "application/x-myapp-project",
+			Comment: "MyApp project",
+			Globs:   []sharedmime.Glob{{Pattern: "*.myapp"}},
+			Parents: []string{"application/json"},
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+*/
+package sharedmime
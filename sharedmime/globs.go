@@ -0,0 +1,170 @@
+package sharedmime
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultGlobWeight is the weight of the patterns defined in globs files,
+	// which do not specify weights.
+	defaultGlobWeight = 50
+
+	// noGlobs is a special pattern which indicates that the patterns of a MIME
+	// type defined in directories with lower precedence must be ignored.
+	noGlobs = "__NOGLOBS__"
+)
+
+// Glob represents a file name pattern associated with a MIME type.
+type Glob struct {
+	// MimeType contains the MIME type associated with the pattern.
+	MimeType string
+
+	// Pattern contains the file name pattern (e.g. *.png).
+	Pattern string
+
+	// Weight contains the weight of the pattern, between 0 and 100. It is
+	// used to choose between patterns matching the same file name.
+	Weight int
+
+	// CaseSensitive specifies whether the pattern is matched case
+	// sensitively.
+	CaseSensitive bool
+}
+
+// globKind classifies patterns in the order in which they are checked.
+type globKind int
+
+const (
+	literalGlob globKind = iota
+	suffixGlob
+	fullGlob
+)
+
+func (g Glob) kind() globKind {
+	switch {
+	case !strings.ContainsAny(g.Pattern, "*?["):
+		return literalGlob
+	case strings.HasPrefix(g.Pattern, "*") && !strings.ContainsAny(g.Pattern[1:], "*?["):
+		return suffixGlob
+	default:
+		return fullGlob
+	}
+}
+
+// match returns true if the specified file name matches the pattern.
+func (g Glob) match(name string) bool {
+	pattern := g.Pattern
+	if !g.CaseSensitive {
+		pattern, name = strings.ToLower(pattern), strings.ToLower(name)
+	}
+
+	switch g.kind() {
+	case literalGlob:
+		return name == pattern
+	case suffixGlob:
+		return strings.HasSuffix(name, pattern[1:])
+	default:
+		ok, err := path.Match(pattern, name)
+		return err == nil && ok
+	}
+}
+
+// parseGlobs parses the globs2 file contained in the provided reader.
+// If legacy is true, the content is parsed using the format of the globs
+// file, which does not include weights and flags. Malformed lines are
+// ignored.
+func parseGlobs(r io.Reader, legacy bool) ([]Glob, error) {
+	var globs []Glob
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		var (
+			glob   = Glob{Weight: defaultGlobWeight}
+			fields []string
+		)
+		if legacy {
+			if fields = strings.SplitN(line, ":", 2); len(fields) != 2 {
+				continue
+			}
+			glob.MimeType, glob.Pattern = fields[0], fields[1]
+		} else {
+			if fields = strings.SplitN(line, ":", 4); len(fields) < 3 {
+				continue
+			}
+
+			weight, err := strconv.Atoi(fields[0])
+			if err != nil || weight < 0 || weight > 100 {
+				continue
+			}
+			glob.Weight, glob.MimeType, glob.Pattern = weight, fields[1], fields[2]
+
+			if len(fields) == 4 {
+				for _, flag := range strings.Split(fields[3], ",") {
+					if flag == "cs" {
+						glob.CaseSensitive = true
+					}
+				}
+			}
+		}
+		if glob.MimeType == "" || glob.Pattern == "" {
+			continue
+		}
+
+		globs = append(globs, glob)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return globs, nil
+}
+
+// matchGlobs returns the patterns matching the specified file name, which
+// are the best candidates for determining its MIME type. Literal patterns
+// are checked first, followed by suffix patterns (e.g. *.png) and, finally,
+// by the rest of the patterns. For each kind of pattern, case sensitive
+// patterns are checked before case insensitive ones. Only the matches of
+// the first check which produces results are considered. Out of those, the
+// patterns with the highest weight are chosen and, if multiple patterns
+// have the same weight, the longest ones are returned.
+func matchGlobs(globs []Glob, name string) []Glob {
+	name = filepath.Base(name)
+
+	for kind := literalGlob; kind <= fullGlob; kind++ {
+		for _, caseSensitive := range []bool{true, false} {
+			var matches []Glob
+			for _, glob := range globs {
+				if glob.kind() != kind || glob.CaseSensitive != caseSensitive || !glob.match(name) {
+					continue
+				}
+
+				if len(matches) > 0 {
+					best := matches[0]
+					if glob.Weight < best.Weight ||
+						(glob.Weight == best.Weight && len(glob.Pattern) < len(best.Pattern)) {
+						continue
+					}
+					if glob.Weight > best.Weight || len(glob.Pattern) > len(best.Pattern) {
+						matches = matches[:0]
+					}
+				}
+				matches = append(matches, glob)
+			}
+			if len(matches) > 0 {
+				return matches
+			}
+		}
+	}
+
+	return nil
+}
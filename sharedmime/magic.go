@@ -0,0 +1,308 @@
+package sharedmime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// magicHeader is the header of magic files.
+	magicHeader = "MIME-Magic\x00\n"
+
+	// noMagic is a special rule which indicates that the magic rules of a
+	// MIME type defined in directories with lower precedence must be ignored.
+	noMagic = "__NOMAGIC__"
+
+	// textCheckLen is the number of bytes checked in order to determine if
+	// the content of a file is textual.
+	textCheckLen = 128
+)
+
+// magicRule represents a rule of a magic section, used to match the
+// content of files.
+type magicRule struct {
+	offset   int
+	rangeLen int
+	value    []byte
+	mask     []byte
+	children []*magicRule
+}
+
+// match returns true if the rule matches the provided data. Rules with
+// nested rules only match if at least one of the nested rules also matches.
+func (r *magicRule) match(data []byte) bool {
+	for offset := r.offset; offset < r.offset+r.rangeLen; offset++ {
+		if offset+len(r.value) > len(data) {
+			return false
+		}
+		if !r.matchAt(data[offset:]) {
+			continue
+		}
+		if len(r.children) == 0 {
+			return true
+		}
+		for _, child := range r.children {
+			if child.match(data) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return false
+}
+
+func (r *magicRule) matchAt(data []byte) bool {
+	if r.mask == nil {
+		return bytes.HasPrefix(data, r.value)
+	}
+
+	for i, b := range r.value {
+		if data[i]&r.mask[i] != b&r.mask[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// extent returns the maximum number of bytes required to evaluate the rule.
+func (r *magicRule) extent() int {
+	extent := r.offset + r.rangeLen - 1 + len(r.value)
+	for _, child := range r.children {
+		extent = max(extent, child.extent())
+	}
+
+	return extent
+}
+
+// magicSection represents a section of a magic file, which contains the
+// rules of a MIME type, along with their priority.
+type magicSection struct {
+	priority int
+	mimeType string
+	rules    []*magicRule
+	noMagic  bool
+}
+
+// match returns true if any of the rules of the section match the
+// provided data.
+func (s *magicSection) match(data []byte) bool {
+	for _, rule := range s.rules {
+		if rule.match(data) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseMagic parses the content of a binary magic file.
+func parseMagic(data []byte) ([]*magicSection, error) {
+	if !bytes.HasPrefix(data, []byte(magicHeader)) {
+		return nil, errors.New("invalid magic file header")
+	}
+	p := &magicParser{data: data, pos: len(magicHeader)}
+
+	var sections []*magicSection
+	for p.pos < len(p.data) {
+		section, err := p.parseSection()
+		if err != nil {
+			return nil, fmt.Errorf("offset %d: %w", p.pos, err)
+		}
+		sections = append(sections, section)
+	}
+
+	return sections, nil
+}
+
+type magicParser struct {
+	data []byte
+	pos  int
+}
+
+func (p *magicParser) parseSection() (*magicSection, error) {
+	// Parse section header, in the [priority:mime-type] format.
+	if p.data[p.pos] != '[' {
+		return nil, errors.New("expected section header")
+	}
+	end := bytes.Index(p.data[p.pos:], []byte("]\n"))
+	if end < 0 {
+		return nil, errors.New("unterminated section header")
+	}
+
+	priority, mimeType, ok := bytes.Cut(p.data[p.pos+1:p.pos+end], []byte(":"))
+	if !ok || len(mimeType) == 0 {
+		return nil, errors.New("invalid section header")
+	}
+	section := &magicSection{mimeType: string(mimeType)}
+
+	var err error
+	if section.priority, err = strconv.Atoi(string(priority)); err != nil {
+		return nil, fmt.Errorf("invalid section priority: %w", err)
+	}
+	p.pos += end + 2
+
+	// Parse rules. The parents of nested rules are tracked by indent.
+	var parents []*magicRule
+	for p.pos < len(p.data) && p.data[p.pos] != '[' {
+		if bytes.HasPrefix(p.data[p.pos:], []byte(noMagic+"\n")) {
+			section.noMagic = true
+			p.pos += len(noMagic) + 1
+			continue
+		}
+
+		indent, rule, err := p.parseRule()
+		if err != nil {
+			return nil, err
+		}
+		if rule == nil {
+			continue
+		}
+
+		switch {
+		case indent == 0:
+			section.rules = append(section.rules, rule)
+		case indent <= len(parents):
+			parent := parents[indent-1]
+			parent.children = append(parent.children, rule)
+		default:
+			return nil, fmt.Errorf("invalid rule indent %d", indent)
+		}
+		parents = append(parents[:indent], rule)
+	}
+
+	return section, nil
+}
+
+// parseRule parses a rule, in the [indent]>offset=value[&mask][~word-size]
+// [+range-length] format. Rules which contain unknown attributes are
+// skipped, in which case a nil rule is returned.
+func (p *magicParser) parseRule() (int, *magicRule, error) {
+	indent := 0
+	if p.data[p.pos] != '>' {
+		var err error
+		if indent, err = p.parseNumber('>'); err != nil {
+			return 0, nil, fmt.Errorf("invalid rule indent: %w", err)
+		}
+	} else {
+		p.pos++
+	}
+
+	offset, err := p.parseNumber('=')
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid rule offset: %w", err)
+	}
+
+	// Parse the value, preceded by its length as a big endian 16-bit integer.
+	if p.pos+2 > len(p.data) {
+		return 0, nil, errors.New("missing rule value length")
+	}
+	length := int(binary.BigEndian.Uint16(p.data[p.pos:]))
+	p.pos += 2
+
+	rule := &magicRule{offset: offset, rangeLen: 1}
+	if rule.value, err = p.read(length); err != nil {
+		return 0, nil, err
+	}
+
+	wordSize := 1
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+
+		switch c {
+		case '&':
+			if rule.mask, err = p.read(length); err != nil {
+				return 0, nil, err
+			}
+		case '~':
+			if wordSize, err = p.parseNumber(0); err != nil {
+				return 0, nil, fmt.Errorf("invalid rule word size: %w", err)
+			}
+		case '+':
+			if rule.rangeLen, err = p.parseNumber(0); err != nil {
+				return 0, nil, fmt.Errorf("invalid rule range length: %w", err)
+			}
+			if rule.rangeLen < 1 {
+				return 0, nil, errors.New("invalid rule range length")
+			}
+		case '\n':
+			swapWords(rule.value, wordSize)
+			swapWords(rule.mask, wordSize)
+			return indent, rule, nil
+		default:
+			// Skip rules containing unknown attributes.
+			end := bytes.IndexByte(p.data[p.pos:], '\n')
+			if end < 0 {
+				return 0, nil, errors.New("unterminated rule")
+			}
+			p.pos += end + 1
+			return indent, nil, nil
+		}
+	}
+
+	return 0, nil, errors.New("unterminated rule")
+}
+
+// parseNumber parses the decimal number at the current position. If end is
+// not zero, the number must be followed by the specified byte, which is
+// consumed.
+func (p *magicParser) parseNumber(end byte) (int, error) {
+	start := p.pos
+	for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+		p.pos++
+	}
+
+	n, err := strconv.Atoi(string(p.data[start:p.pos]))
+	if err != nil {
+		return 0, err
+	}
+	if end != 0 {
+		if p.pos == len(p.data) || p.data[p.pos] != end {
+			return 0, fmt.Errorf("expected `%c`", end)
+		}
+		p.pos++
+	}
+
+	return n, nil
+}
+
+func (p *magicParser) read(n int) ([]byte, error) {
+	if p.pos+n > len(p.data) {
+		return nil, errors.New("unexpected end of file")
+	}
+
+	b := p.data[p.pos : p.pos+n]
+	p.pos += n
+	return b, nil
+}
+
+// swapWords converts the specified big endian words of the specified size
+// to the byte order of the host, in place. Words of size 1 are not changed.
+func swapWords(b []byte, size int) {
+	if size < 2 || len(b)%size != 0 || binary.NativeEndian.Uint16([]byte{0, 1}) == 1 {
+		return
+	}
+
+	for i := 0; i < len(b); i += size {
+		for j, k := i, i+size-1; j < k; j, k = j+1, k-1 {
+			b[j], b[k] = b[k], b[j]
+		}
+	}
+}
+
+// fallbackType returns the MIME type of content which does not match any
+// magic rules. Content which does not contain ASCII control characters,
+// other than whitespace, in its first bytes is considered textual.
+func fallbackType(data []byte) string {
+	for _, b := range data[:min(len(data), textCheckLen)] {
+		if b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' || b == 0x7f {
+			return "application/octet-stream"
+		}
+	}
+
+	return "text/plain"
+}
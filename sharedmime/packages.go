@@ -0,0 +1,446 @@
+package sharedmime
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/adrg/xdg/internal/fileutil"
+)
+
+const (
+	// packagesDir is the subdirectory of a mime directory which contains
+	// the source XML files of the database.
+	packagesDir = "packages"
+
+	// generatedHeader is written at the beginning of the generated database
+	// files.
+	generatedHeader = "# This file was automatically generated from the source\n" +
+		"# XML files of the packages directory.\n" +
+		"#\n" +
+		"# Do not edit!\n"
+
+	xmlNamespace = "http://www.freedesktop.org/standards/shared-mime-info"
+)
+
+// TypeDefinition describes a MIME type registered using Register.
+type TypeDefinition struct {
+	// Type contains the name of the MIME type (e.g. application/x-foo).
+	Type string
+
+	// Comment contains the human-readable description of the MIME type.
+	Comment string
+
+	// LocalizedComments contains the localized descriptions of the MIME
+	// type, indexed by locale (e.g. fr or pt_BR).
+	LocalizedComments map[string]string
+
+	// Globs contains the file name patterns of the MIME type. The MimeType
+	// field of the patterns is ignored and a zero weight is replaced with
+	// the default weight of 50.
+	Globs []Glob
+
+	// Aliases contains the alternative names of the MIME type.
+	Aliases []string
+
+	// Parents contains the MIME types which the MIME type is a subclass of.
+	Parents []string
+
+	// Icon contains the name of the icon of the MIME type.
+	Icon string
+
+	// GenericIcon contains the name of the generic icon of the MIME type.
+	GenericIcon string
+}
+
+// packageXML represents the content of the source XML files of a database.
+type packageXML struct {
+	XMLName   xml.Name      `xml:"mime-info"`
+	Namespace string        `xml:"xmlns,attr"`
+	Types     []typeElement `xml:"mime-type"`
+}
+
+type typeElement struct {
+	XMLName     xml.Name          `xml:"mime-type"`
+	Namespace   string            `xml:"xmlns,attr,omitempty"`
+	Type        string            `xml:"type,attr"`
+	Comments    []commentElement  `xml:"comment"`
+	Globs       []globElement     `xml:"glob"`
+	DeleteGlobs *struct{}         `xml:"glob-deleteall"`
+	Aliases     []typeAttrElement `xml:"alias"`
+	Parents     []typeAttrElement `xml:"sub-class-of"`
+	Icon        *nameAttrElement  `xml:"icon"`
+	GenericIcon *nameAttrElement  `xml:"generic-icon"`
+}
+
+type commentElement struct {
+	Lang string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+type globElement struct {
+	Pattern       string `xml:"pattern,attr"`
+	Weight        string `xml:"weight,attr,omitempty"`
+	CaseSensitive string `xml:"case-sensitive,attr,omitempty"`
+}
+
+type typeAttrElement struct {
+	Type string `xml:"type,attr"`
+}
+
+type nameAttrElement struct {
+	Name string `xml:"name,attr"`
+}
+
+// Register writes the specified MIME type definitions to a source XML file
+// of the shared MIME-info database located in xdg.DataHome, named after the
+// specified package name (e.g. $XDG_DATA_HOME/mime/packages/myapp.xml), and
+// regenerates the database files of the directory, so that the MIME types
+// are recognized immediately. Existing definitions of the same package are
+// replaced.
+//
+// Only a subset of the update-mime-database utility is implemented: the
+// globs2, globs, aliases, subclasses, icons, generic-icons and types files
+// are generated, along with the <media>/<subtype>.xml files. Magic rules
+// are not generated. The binary mime.cache file of the directory is
+// removed, if it exists, so that applications fall back to reading the
+// generated files.
+func Register(pkg string, types ...TypeDefinition) error {
+	name, err := packagePath(pkg)
+	if err != nil {
+		return err
+	}
+
+	info := packageXML{Namespace: xmlNamespace}
+	for _, def := range types {
+		elem, err := def.element()
+		if err != nil {
+			return err
+		}
+		info.Types = append(info.Types, elem)
+	}
+
+	data, err := xml.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), append(data, '\n')...)
+
+	if err := os.MkdirAll(filepath.Dir(name), os.ModeDir|0o700); err != nil {
+		return err
+	}
+	if err := fileutil.WriteAtomic(name, data, 0o644); err != nil {
+		return err
+	}
+
+	return update(filepath.Dir(filepath.Dir(name)))
+}
+
+// Unregister removes the source XML file of the shared MIME-info database
+// located in xdg.DataHome, written by Register for the specified package
+// name, and regenerates the database files of the directory.
+func Unregister(pkg string) error {
+	name, err := packagePath(pkg)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil {
+		return err
+	}
+
+	return update(filepath.Dir(filepath.Dir(name)))
+}
+
+func packagePath(pkg string) (string, error) {
+	if pkg == "" || pkg == "." || pkg == ".." || strings.ContainsAny(pkg, `/\`) {
+		return "", fmt.Errorf("invalid package name `%s`", pkg)
+	}
+
+	return filepath.Join(xdg.DataHome, "mime", packagesDir, pkg+".xml"), nil
+}
+
+func (def TypeDefinition) element() (typeElement, error) {
+	if !validType(def.Type) {
+		return typeElement{}, fmt.Errorf("invalid MIME type `%s`", def.Type)
+	}
+
+	elem := typeElement{Type: def.Type}
+	if def.Comment != "" {
+		elem.Comments = append(elem.Comments, commentElement{Text: def.Comment})
+	}
+	langs := make([]string, 0, len(def.LocalizedComments))
+	for lang := range def.LocalizedComments {
+		langs = append(langs, lang)
+	}
+	slices.Sort(langs)
+	for _, lang := range langs {
+		elem.Comments = append(elem.Comments, commentElement{Lang: lang, Text: def.LocalizedComments[lang]})
+	}
+
+	for _, glob := range def.Globs {
+		if glob.Pattern == "" || strings.ContainsAny(glob.Pattern, ":\n") ||
+			glob.Weight < 0 || glob.Weight > 100 {
+			return typeElement{}, fmt.Errorf("invalid pattern `%s` for MIME type `%s`", glob.Pattern, def.Type)
+		}
+
+		ge := globElement{Pattern: glob.Pattern}
+		if glob.Weight != 0 && glob.Weight != defaultGlobWeight {
+			ge.Weight = strconv.Itoa(glob.Weight)
+		}
+		if glob.CaseSensitive {
+			ge.CaseSensitive = "true"
+		}
+		elem.Globs = append(elem.Globs, ge)
+	}
+
+	for _, alias := range def.Aliases {
+		if !validType(alias) {
+			return typeElement{}, fmt.Errorf("invalid alias `%s` for MIME type `%s`", alias, def.Type)
+		}
+		elem.Aliases = append(elem.Aliases, typeAttrElement{Type: alias})
+	}
+	for _, parent := range def.Parents {
+		if !validType(parent) {
+			return typeElement{}, fmt.Errorf("invalid parent `%s` for MIME type `%s`", parent, def.Type)
+		}
+		elem.Parents = append(elem.Parents, typeAttrElement{Type: parent})
+	}
+	if def.Icon != "" {
+		elem.Icon = &nameAttrElement{Name: def.Icon}
+	}
+	if def.GenericIcon != "" {
+		elem.GenericIcon = &nameAttrElement{Name: def.GenericIcon}
+	}
+
+	return elem, nil
+}
+
+// validType returns true if the specified MIME type is in the
+// media/subtype format and can be safely used as a relative path.
+func validType(mimeType string) bool {
+	media, subtype, ok := strings.Cut(mimeType, "/")
+	return ok && media != "" && subtype != "" && media != "." && media != ".." &&
+		subtype != "." && subtype != ".." && !strings.ContainsAny(mimeType, " \t\n:\\") &&
+		!strings.Contains(subtype, "/")
+}
+
+// update regenerates the database files of the specified mime directory,
+// based on its source XML files. The source files are processed in lexical
+// order, except for the Override.xml file, which is processed last.
+func update(dir string) error {
+	entries, err := os.ReadDir(filepath.Join(dir, packagesDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && strings.HasSuffix(name, ".xml") && name != "Override.xml" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	if slices.ContainsFunc(entries, func(entry os.DirEntry) bool { return entry.Name() == "Override.xml" }) {
+		names = append(names, "Override.xml")
+	}
+
+	// Merge the definitions of the source files, indexed by MIME type.
+	var (
+		types []string
+		elems = map[string]*typeElement{}
+	)
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, packagesDir, name))
+		if err != nil {
+			return err
+		}
+
+		var info packageXML
+		if err := xml.Unmarshal(data, &info); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		for _, elem := range info.Types {
+			if !validType(elem.Type) {
+				return fmt.Errorf("%s: invalid MIME type `%s`", name, elem.Type)
+			}
+			existing, ok := elems[elem.Type]
+			if !ok {
+				types = append(types, elem.Type)
+				elems[elem.Type] = &elem
+				continue
+			}
+
+			existing.merge(elem)
+		}
+	}
+	slices.Sort(types)
+
+	return writeDatabase(dir, types, elems)
+}
+
+// merge merges the definition of the same MIME type from a source file
+// processed later.
+func (e *typeElement) merge(other typeElement) {
+	if other.DeleteGlobs != nil {
+		e.Globs, e.DeleteGlobs = nil, other.DeleteGlobs
+	}
+	e.Globs = append(e.Globs, other.Globs...)
+	e.Aliases = append(e.Aliases, other.Aliases...)
+	e.Parents = append(e.Parents, other.Parents...)
+	if len(other.Comments) > 0 {
+		e.Comments = other.Comments
+	}
+	if other.Icon != nil {
+		e.Icon = other.Icon
+	}
+	if other.GenericIcon != nil {
+		e.GenericIcon = other.GenericIcon
+	}
+}
+
+func writeDatabase(dir string, types []string, elems map[string]*typeElement) error {
+	// Read the MIME types of the previously generated database, in order to
+	// remove the XML files of the ones which are no longer defined.
+	staleTypes, err := readTypes(filepath.Join(dir, "types"))
+	if err != nil {
+		return err
+	}
+	staleTypes = slices.DeleteFunc(staleTypes, func(mimeType string) bool {
+		_, found := slices.BinarySearch(types, mimeType)
+		return found || !validType(mimeType)
+	})
+
+	var (
+		globs2, globs, aliases, subclasses bytes.Buffer
+		icons, genericIcons, typeList      bytes.Buffer
+		globLines                          []string
+	)
+	for _, mimeType := range types {
+		elem := elems[mimeType]
+		fmt.Fprintln(&typeList, mimeType)
+
+		if elem.DeleteGlobs != nil {
+			globLines = append(globLines, fmt.Sprintf("%d:%s:%s", defaultGlobWeight, mimeType, noGlobs))
+		}
+		for _, glob := range elem.Globs {
+			weight := defaultGlobWeight
+			if glob.Weight != "" {
+				var err error
+				if weight, err = strconv.Atoi(glob.Weight); err != nil || weight < 0 || weight > 100 {
+					return fmt.Errorf("invalid weight `%s` for MIME type `%s`", glob.Weight, mimeType)
+				}
+			}
+
+			line := fmt.Sprintf("%d:%s:%s", weight, mimeType, glob.Pattern)
+			if glob.CaseSensitive == "true" {
+				line += ":cs"
+			}
+			globLines = append(globLines, line)
+			fmt.Fprintf(&globs, "%s:%s\n", mimeType, glob.Pattern)
+		}
+
+		for _, alias := range elem.Aliases {
+			fmt.Fprintf(&aliases, "%s %s\n", alias.Type, mimeType)
+		}
+		for _, parent := range elem.Parents {
+			fmt.Fprintf(&subclasses, "%s %s\n", mimeType, parent.Type)
+		}
+		if elem.Icon != nil {
+			fmt.Fprintf(&icons, "%s:%s\n", mimeType, elem.Icon.Name)
+		}
+		if elem.GenericIcon != nil {
+			fmt.Fprintf(&genericIcons, "%s:%s\n", mimeType, elem.GenericIcon.Name)
+		}
+	}
+
+	// Sort patterns by weight, in descending order.
+	slices.SortStableFunc(globLines, func(a, b string) int {
+		wa, _, _ := strings.Cut(a, ":")
+		wb, _, _ := strings.Cut(b, ":")
+		ia, _ := strconv.Atoi(wa)
+		ib, _ := strconv.Atoi(wb)
+		return ib - ia
+	})
+	for _, line := range globLines {
+		globs2.WriteString(line + "\n")
+	}
+
+	files := map[string][]byte{
+		"globs2":        append([]byte(generatedHeader), globs2.Bytes()...),
+		"globs":         append([]byte(generatedHeader), globs.Bytes()...),
+		"aliases":       aliases.Bytes(),
+		"subclasses":    subclasses.Bytes(),
+		"icons":         icons.Bytes(),
+		"generic-icons": genericIcons.Bytes(),
+		"types":         typeList.Bytes(),
+	}
+	for name, data := range files {
+		if err := fileutil.WriteAtomic(filepath.Join(dir, name), data, 0o644); err != nil {
+			return err
+		}
+	}
+
+	// Write the XML files of the MIME types.
+	for _, mimeType := range types {
+		elem := *elems[mimeType]
+		elem.XMLName, elem.Namespace, elem.DeleteGlobs = xml.Name{}, xmlNamespace, nil
+
+		data, err := xml.MarshalIndent(elem, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append([]byte(xml.Header), append(data, '\n')...)
+
+		name := filepath.Join(dir, filepath.FromSlash(mimeType)+".xml")
+		if err := os.MkdirAll(filepath.Dir(name), os.ModeDir|0o755); err != nil {
+			return err
+		}
+		if err := fileutil.WriteAtomic(name, data, 0o644); err != nil {
+			return err
+		}
+	}
+
+	// Remove the XML files of the MIME types which are no longer defined.
+	for _, mimeType := range staleTypes {
+		name := filepath.Join(dir, filepath.FromSlash(mimeType)+".xml")
+		if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	// Remove the binary cache, which would otherwise take precedence over
+	// the generated files.
+	if err := os.Remove(filepath.Join(dir, "mime.cache")); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// readTypes returns the MIME types listed in the specified types file.
+// A missing file is not considered an error.
+func readTypes(name string) ([]string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var types []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && line[0] != '#' {
+			types = append(types, line)
+		}
+	}
+
+	return types, nil
+}
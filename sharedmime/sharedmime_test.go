@@ -0,0 +1,481 @@
+package sharedmime_test
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/adrg/xdg"
+	"github.com/adrg/xdg/sharedmime"
+)
+
+const systemGlobs2 = `# Glob patterns.
+50:text/x-readme:README*
+50:text/plain:*.txt
+50:application/gzip:*.gz
+50:application/x-compressed-tar:*.tar.gz
+50:text/x-makefile:makefile
+50:text/x-makefile:GNUmakefile
+10:text/x-makefile:Makefile:cs
+50:text/x-c++src:*.C:cs
+50:text/x-csrc:*.c
+50:audio/x-mod:*.mod
+50:text/x-go-mod:go.mod
+40:application/x-theme:*.theme
+40:application/x-desktop:*.theme
+30:application/x-low:*.low
+60:application/x-high:*.low
+invalid line
+abc:text/invalid:*.invalid
+50:application/x-old:*.old
+`
+
+// writeFile writes the specified content to the specified path, creating
+// the parent directories if needed.
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModeDir|0o700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestDirs(t *testing.T) {
+	dirs := sharedmime.Dirs()
+	require.Len(t, dirs, len(xdg.DataDirs)+1)
+	require.Equal(t, filepath.Join(xdg.DataHome, "mime"), dirs[0])
+	for i, dir := range xdg.DataDirs {
+		require.Equal(t, filepath.Join(dir, "mime"), dirs[i+1])
+	}
+}
+
+func TestTypesByFilename(t *testing.T) {
+	root := t.TempDir()
+	userDir, systemDir, legacyDir := filepath.Join(root, "user"), filepath.Join(root, "system"), filepath.Join(root, "legacy")
+
+	writeFile(t, filepath.Join(systemDir, "globs2"), systemGlobs2)
+	writeFile(t, filepath.Join(systemDir, "globs"), "text/x-ignored:*.txt\n")
+	writeFile(t, filepath.Join(legacyDir, "globs"), "# Legacy patterns.\napplication/x-legacy:*.legacy\napplication/x-old:*.older\n")
+	writeFile(t, filepath.Join(userDir, "globs2"), "50:application/x-old:__NOGLOBS__\n50:application/x-user:*.user:cs\n")
+
+	db, err := sharedmime.LoadDirs(userDir, filepath.Join(root, "missing"), systemDir, legacyDir)
+	require.NoError(t, err)
+
+	tests := map[string][]string{
+		// Suffix patterns.
+		"notes.txt":            {"text/plain"},
+		"/home/user/NOTES.TXT": {"text/plain"},
+		"archive.gz":           {"application/gzip"},
+		"archive.tar.gz":       {"application/x-compressed-tar"},
+		"data.legacy":          {"application/x-legacy"},
+		"data.user":            {"application/x-user"},
+		"data.USER":            nil,
+
+		// Case sensitive patterns.
+		"main.C": {"text/x-c++src"},
+		"main.c": {"text/x-csrc"},
+
+		// Literal patterns take precedence over suffix patterns.
+		"go.mod":      {"text/x-go-mod"},
+		"project.mod": {"audio/x-mod"},
+		"Makefile":    {"text/x-makefile"},
+		"MAKEFILE":    {"text/x-makefile"},
+
+		// Full patterns.
+		"README.md": {"text/x-readme"},
+		"readme":    {"text/x-readme"},
+
+		// Weights and conflicts.
+		"file.low":   {"application/x-high"},
+		"file.theme": {"application/x-theme", "application/x-desktop"},
+
+		// Discarded patterns.
+		"file.old":   nil,
+		"file.older": nil,
+
+		// Unknown file names.
+		"file.invalid": nil,
+		"file":         nil,
+	}
+	for name, expected := range tests {
+		require.Equal(t, expected, db.TypesByFilename(name), name)
+	}
+
+	require.Equal(t, "application/x-theme", db.TypeByFilename("default.theme"))
+	require.Equal(t, "", db.TypeByFilename("file"))
+
+	globs := db.Globs()
+	require.Equal(t, sharedmime.Glob{
+		MimeType:      "application/x-user",
+		Pattern:       "*.user",
+		Weight:        50,
+		CaseSensitive: true,
+	}, globs[0])
+	require.Equal(t, sharedmime.Glob{
+		MimeType: "application/x-legacy",
+		Pattern:  "*.legacy",
+		Weight:   50,
+	}, globs[len(globs)-1])
+}
+
+func TestLoad(t *testing.T) {
+	db, err := sharedmime.Load()
+	require.NoError(t, err)
+	require.NotNil(t, db)
+}
+
+// magicRule returns the binary representation of a magic rule.
+func magicRule(indent, offset int, value, mask string, extra string) string {
+	var sb strings.Builder
+	if indent > 0 {
+		sb.WriteString(strconv.Itoa(indent))
+	}
+	sb.WriteString(">" + strconv.Itoa(offset) + "=")
+	sb.Write(binary.BigEndian.AppendUint16(nil, uint16(len(value))))
+	sb.WriteString(value)
+	if mask != "" {
+		sb.WriteString("&" + mask)
+	}
+	sb.WriteString(extra + "\n")
+
+	return sb.String()
+}
+
+func TestTypeByContent(t *testing.T) {
+	root := t.TempDir()
+	userDir, systemDir := filepath.Join(root, "user"), filepath.Join(root, "system")
+
+	writeFile(t, filepath.Join(systemDir, "magic"), "MIME-Magic\x00\n"+
+		"[50:image/png]\n"+magicRule(0, 0, "\x89PNG", "", "")+
+		"[80:application/x-nested]\n"+
+		magicRule(0, 0, "NEST", "", "")+
+		magicRule(1, 4, "A", "", "")+
+		magicRule(2, 5, "B", "", "")+
+		magicRule(1, 4, "C", "", "")+
+		"[40:application/x-masked]\n"+magicRule(0, 0, "\xf0\x0f", "\xf0\x0f", "")+
+		"[40:application/x-range]\n"+magicRule(0, 2, "RNG", "", "+4")+
+		"[40:application/x-word]\n"+magicRule(0, 0, "\x12\x34", "", "~2")+
+		"[40:application/x-unknown]\n"+magicRule(0, 0, "UNK", "", "!future")+
+		"[30:application/x-old]\n"+magicRule(0, 0, "OLD", "", "")+
+		"[30:application/x-desktop]\n"+magicRule(0, 0, "[Desktop Entry]", "", ""),
+	)
+	writeFile(t, filepath.Join(userDir, "magic"), "MIME-Magic\x00\n"+
+		"[30:application/x-old]\n__NOMAGIC__\n"+
+		"[90:application/x-user]\n"+magicRule(0, 0, "\x89PNGUSER", "", ""),
+	)
+	writeFile(t, filepath.Join(systemDir, "globs2"), systemGlobs2)
+	writeFile(t, filepath.Join(systemDir, "subclasses"), "application/x-theme application/x-desktop\n")
+
+	db, err := sharedmime.LoadDirs(userDir, systemDir)
+	require.NoError(t, err)
+
+	word := binary.NativeEndian.AppendUint16(nil, 0x1234)
+	tests := map[string]string{
+		"\x89PNG\r\n":         "image/png",
+		"\x89PNGUSER":         "application/x-user",
+		"NESTAB":              "application/x-nested",
+		"NESTAX":              "text/plain",
+		"NESTC":               "application/x-nested",
+		"\xf5\x3f":            "application/x-masked",
+		"\x0f\xf0":            "application/octet-stream",
+		"xxxxxRNG":            "application/x-range",
+		"xxxxxxxRNG":          "text/plain",
+		string(word) + "\xff": "application/x-word",
+		"UNK":                 "text/plain",
+		"OLD":                 "text/plain",
+		"plain text\n":        "text/plain",
+		"":                    "text/plain",
+		"binary\x00\x01\x02":  "application/octet-stream",
+	}
+	for content, expected := range tests {
+		mimeType, err := db.TypeByContent(strings.NewReader(content))
+		require.NoError(t, err)
+		require.Equal(t, expected, mimeType, strconv.Quote(content))
+	}
+
+	// Test matching both the file name and the content.
+	typeTests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "image.txt", content: "\x89PNG", expected: "text/plain"},
+		{name: "image", content: "\x89PNG", expected: "image/png"},
+		{name: "", content: "\x89PNG", expected: "image/png"},
+		{name: "app.theme", content: "[Desktop Entry]", expected: "application/x-theme"},
+		{name: "app.theme", content: "\x89PNG", expected: "image/png"},
+		{name: "app.theme", content: "unknown", expected: "application/x-theme"},
+		{name: "unknown", content: "plain", expected: "text/plain"},
+	}
+	for _, test := range typeTests {
+		mimeType, err := db.TypeByReader(test.name, strings.NewReader(test.content))
+		require.NoError(t, err)
+		require.Equal(t, test.expected, mimeType, test.name)
+	}
+
+	mimeType, err := db.TypeByReader("app.theme", nil)
+	require.NoError(t, err)
+	require.Equal(t, "application/x-theme", mimeType)
+
+	mimeType, err = db.TypeByReader("unknown", nil)
+	require.NoError(t, err)
+	require.Equal(t, "application/octet-stream", mimeType)
+
+	// Test files.
+	dir := t.TempDir()
+	name := filepath.Join(dir, "image")
+	require.NoError(t, os.WriteFile(name, []byte("\x89PNG"), 0o600))
+
+	mimeType, err = db.TypeByFile(name)
+	require.NoError(t, err)
+	require.Equal(t, "image/png", mimeType)
+
+	mimeType, err = db.TypeByFile(dir)
+	require.NoError(t, err)
+	require.Equal(t, "inode/directory", mimeType)
+
+	_, err = db.TypeByFile(filepath.Join(dir, "missing"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoadInvalidMagic(t *testing.T) {
+	for _, content := range []string{
+		"invalid header",
+		"MIME-Magic\x00\n[50:image/png\n",
+		"MIME-Magic\x00\n[x:image/png]\n",
+		"MIME-Magic\x00\n>0=",
+		"MIME-Magic\x00\n[50:image/png]\n>0=\x00\x04PN",
+		"MIME-Magic\x00\n[50:image/png]\n2>0=\x00\x01P\n",
+		"MIME-Magic\x00\n[50:image/png]\n>0=\x00\x01P+0\n",
+	} {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "magic"), content)
+
+		_, err := sharedmime.LoadDirs(dir)
+		require.Error(t, err, strconv.Quote(content))
+	}
+}
+
+func TestTypeInfo(t *testing.T) {
+	root := t.TempDir()
+	userDir, systemDir := filepath.Join(root, "user"), filepath.Join(root, "system")
+
+	writeFile(t, filepath.Join(systemDir, "aliases"), "# Aliases.\napplication/x-pdf application/pdf\nimage/x-png image/png\ninvalid\n")
+	writeFile(t, filepath.Join(systemDir, "subclasses"), "application/x-shellscript application/x-executable\napplication/x-shellscript text/plain\napplication/x-app application/xml\napplication/xml text/plain\n")
+	writeFile(t, filepath.Join(systemDir, "icons"), "application/x-app:app-icon\n")
+	writeFile(t, filepath.Join(systemDir, "generic-icons"), "application/pdf:x-office-document\napplication/x-app:package-x-generic\n")
+	writeFile(t, filepath.Join(userDir, "aliases"), "application/x-app-old application/x-app\n")
+	writeFile(t, filepath.Join(userDir, "subclasses"), "application/x-app application/zip\n")
+	writeFile(t, filepath.Join(userDir, "icons"), "application/x-app:user-app-icon\n")
+
+	db, err := sharedmime.LoadDirs(userDir, systemDir)
+	require.NoError(t, err)
+
+	// Test aliases.
+	require.Equal(t, "application/pdf", db.Canonical("application/x-pdf"))
+	require.Equal(t, "image/png", db.Canonical("IMAGE/X-PNG"))
+	require.Equal(t, "application/x-app", db.Canonical("application/x-app-old"))
+	require.Equal(t, "image/jpeg", db.Canonical("image/jpeg"))
+
+	// Test subclasses.
+	require.Equal(t, []string{"application/x-executable", "text/plain", "application/octet-stream"}, db.Parents("application/x-shellscript"))
+	require.Equal(t, []string{"application/zip", "application/xml", "application/octet-stream"}, db.Parents("application/x-app-old"))
+	require.Equal(t, []string{"text/plain", "application/octet-stream"}, db.Parents("text/x-csrc"))
+	require.Equal(t, []string{"application/octet-stream"}, db.Parents("text/plain"))
+	require.Empty(t, db.Parents("application/octet-stream"))
+	require.Empty(t, db.Parents("inode/directory"))
+
+	require.True(t, db.IsSubclassOf("application/x-app", "application/x-app"))
+	require.True(t, db.IsSubclassOf("application/x-app-old", "text/plain"))
+	require.True(t, db.IsSubclassOf("application/x-app", "application/zip"))
+	require.True(t, db.IsSubclassOf("image/x-png", "application/octet-stream"))
+	require.True(t, db.IsSubclassOf("text/x-csrc", "text/plain"))
+	require.False(t, db.IsSubclassOf("text/plain", "text/x-csrc"))
+	require.False(t, db.IsSubclassOf("image/png", "text/plain"))
+	require.False(t, db.IsSubclassOf("inode/directory", "application/octet-stream"))
+
+	// Test icons.
+	require.Equal(t, "user-app-icon", db.Icon("application/x-app"))
+	require.Equal(t, "application-pdf", db.Icon("application/x-pdf"))
+	require.Equal(t, "package-x-generic", db.GenericIcon("application/x-app"))
+	require.Equal(t, "x-office-document", db.GenericIcon("application/x-pdf"))
+	require.Equal(t, "audio-x-generic", db.GenericIcon("audio/ogg"))
+
+	// Test mixed-case MIME types.
+	const macroDoc = "application/vnd.ms-word.document.macroEnabled.12"
+	mixedDir := filepath.Join(root, "mixed")
+	writeFile(t, filepath.Join(mixedDir, "globs2"), "50:"+macroDoc+":*.docm\n")
+	writeFile(t, filepath.Join(mixedDir, "aliases"), "application/x-Macro-Doc "+macroDoc+"\n")
+	writeFile(t, filepath.Join(mixedDir, "subclasses"), macroDoc+" application/zip\n")
+	writeFile(t, filepath.Join(mixedDir, "icons"), macroDoc+":x-office-document\n")
+
+	db, err = sharedmime.LoadDirs(mixedDir)
+	require.NoError(t, err)
+
+	require.Equal(t, macroDoc, db.Canonical(macroDoc))
+	require.Equal(t, macroDoc, db.Canonical("APPLICATION/VND.MS-WORD.DOCUMENT.MACROENABLED.12"))
+	require.Equal(t, macroDoc, db.Canonical("application/x-macro-doc"))
+	require.Equal(t, []string{"application/zip", "application/octet-stream"}, db.Parents(macroDoc))
+	require.True(t, db.IsSubclassOf("application/x-macro-doc", "Application/Zip"))
+	require.Equal(t, "x-office-document", db.Icon(macroDoc))
+}
+
+func TestComment(t *testing.T) {
+	root := t.TempDir()
+	userDir, systemDir := filepath.Join(root, "user"), filepath.Join(root, "system")
+
+	writeFile(t, filepath.Join(systemDir, "aliases"), "image/x-png image/png\n")
+	writeFile(t, filepath.Join(systemDir, "image", "png.xml"), `<?xml version="1.0" encoding="utf-8"?>
+<mime-type xmlns="http://www.freedesktop.org/standards/shared-mime-info" type="image/png">
+  <comment>PNG image</comment>
+  <comment xml:lang="fr">image PNG</comment>
+  <comment xml:lang="pt_BR">imagem PNG</comment>
+  <comment xml:lang="sr@latin">PNG slika</comment>
+</mime-type>
+`)
+	writeFile(t, filepath.Join(systemDir, "text", "plain.xml"), `<?xml version="1.0" encoding="utf-8"?>
+<mime-type xmlns="http://www.freedesktop.org/standards/shared-mime-info" type="text/plain">
+  <comment>plain text document</comment>
+</mime-type>
+`)
+	writeFile(t, filepath.Join(userDir, "text", "plain.xml"), `<?xml version="1.0" encoding="utf-8"?>
+<mime-type xmlns="http://www.freedesktop.org/standards/shared-mime-info" type="text/plain">
+  <comment>user text document</comment>
+</mime-type>
+`)
+	writeFile(t, filepath.Join(systemDir, "text", "x-invalid.xml"), "<mime-type>")
+
+	db, err := sharedmime.LoadDirs(userDir, systemDir)
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"C":                 "PNG image",
+		"de_DE.UTF-8":       "PNG image",
+		"fr_FR.UTF-8":       "image PNG",
+		"pt_BR.UTF-8":       "imagem PNG",
+		"pt_PT.UTF-8":       "PNG image",
+		"sr_RS.UTF-8@latin": "PNG slika",
+	}
+	for name, expected := range tests {
+		t.Setenv("LC_ALL", name)
+
+		comment, err := db.Comment("image/x-png")
+		require.NoError(t, err)
+		require.Equal(t, expected, comment, name)
+	}
+
+	comment, err := db.Comment("text/plain")
+	require.NoError(t, err)
+	require.Equal(t, "user text document", comment)
+
+	for _, mimeType := range []string{"image/jpeg", "text/x-invalid", "invalid", "image/../png", "../image/png"} {
+		_, err = db.Comment(mimeType)
+		require.Error(t, err, mimeType)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	t.Cleanup(xdg.Reload)
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	xdg.Reload()
+
+	mimeDir := filepath.Join(dataHome, "mime")
+	writeFile(t, filepath.Join(mimeDir, "mime.cache"), "stale")
+	writeFile(t, filepath.Join(mimeDir, "packages", "Override.xml"), `<?xml version="1.0" encoding="utf-8"?>
+<mime-info xmlns="http://www.freedesktop.org/standards/shared-mime-info">
+  <mime-type type="application/x-foo">
+    <glob-deleteall/>
+    <glob pattern="*.foo2"/>
+    <icon name="override-icon"/>
+  </mime-type>
+</mime-info>
+`)
+
+	err := sharedmime.Register("xdg-test",
+		sharedmime.TypeDefinition{
+			Type:              "application/x-foo",
+			Comment:           "Foo document",
+			LocalizedComments: map[string]string{"fr": "document Foo"},
+			Globs: []sharedmime.Glob{
+				{Pattern: "*.foo"},
+			},
+			Aliases:     []string{"application/foo"},
+			Parents:     []string{"text/plain"},
+			GenericIcon: "text-x-generic",
+		},
+		sharedmime.TypeDefinition{
+			Type: "application/x-bar",
+			Globs: []sharedmime.Glob{
+				{Pattern: "*.bar", Weight: 30},
+				{Pattern: "Barfile", Weight: 80, CaseSensitive: true},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(mimeDir, "packages", "xdg-test.xml"))
+	require.FileExists(t, filepath.Join(mimeDir, "application", "x-foo.xml"))
+	require.NoFileExists(t, filepath.Join(mimeDir, "mime.cache"))
+
+	db, err := sharedmime.LoadDirs(mimeDir)
+	require.NoError(t, err)
+	require.Equal(t, "", db.TypeByFilename("test.foo"))
+	require.Equal(t, "application/x-foo", db.TypeByFilename("test.foo2"))
+	require.Equal(t, "application/x-bar", db.TypeByFilename("Barfile"))
+	require.Equal(t, "", db.TypeByFilename("barfile"))
+	require.Equal(t, "application/x-bar", db.TypeByFilename("test.BAR"))
+	require.Equal(t, "application/x-foo", db.Canonical("application/foo"))
+	require.True(t, db.IsSubclassOf("application/foo", "text/plain"))
+	require.Equal(t, "override-icon", db.Icon("application/x-foo"))
+	require.Equal(t, "text-x-generic", db.GenericIcon("application/x-foo"))
+
+	t.Setenv("LC_ALL", "fr_FR.UTF-8")
+	comment, err := db.Comment("application/x-foo")
+	require.NoError(t, err)
+	require.Equal(t, "document Foo", comment)
+
+	// Test re-registering a package with fewer MIME types.
+	require.NoError(t, sharedmime.Register("xdg-test-extra",
+		sharedmime.TypeDefinition{Type: "application/x-baz"},
+		sharedmime.TypeDefinition{Type: "application/x-qux"},
+	))
+	require.FileExists(t, filepath.Join(mimeDir, "application", "x-qux.xml"))
+	require.NoError(t, sharedmime.Register("xdg-test-extra",
+		sharedmime.TypeDefinition{Type: "application/x-baz"},
+	))
+	require.FileExists(t, filepath.Join(mimeDir, "application", "x-baz.xml"))
+	require.NoFileExists(t, filepath.Join(mimeDir, "application", "x-qux.xml"))
+	require.NoError(t, sharedmime.Unregister("xdg-test-extra"))
+	require.NoFileExists(t, filepath.Join(mimeDir, "application", "x-baz.xml"))
+
+	// Unregister package.
+	require.NoError(t, sharedmime.Unregister("xdg-test"))
+	require.NoFileExists(t, filepath.Join(mimeDir, "packages", "xdg-test.xml"))
+	require.NoFileExists(t, filepath.Join(mimeDir, "application", "x-bar.xml"))
+	require.FileExists(t, filepath.Join(mimeDir, "application", "x-foo.xml"))
+	require.Error(t, sharedmime.Unregister("xdg-test"))
+
+	db, err = sharedmime.LoadDirs(mimeDir)
+	require.NoError(t, err)
+	require.Equal(t, "", db.TypeByFilename("test.bar"))
+	require.Equal(t, "application/x-foo", db.TypeByFilename("test.foo2"))
+	require.Equal(t, "application/foo", db.Canonical("application/foo"))
+
+	// Invalid definitions.
+	for _, pkg := range []string{"", "..", "a/b"} {
+		require.Error(t, sharedmime.Register(pkg), pkg)
+	}
+	invalid := []sharedmime.TypeDefinition{
+		{Type: "invalid"},
+		{Type: "application/../x"},
+		{Type: "application/x-foo", Globs: []sharedmime.Glob{{Pattern: "a:b"}}},
+		{Type: "application/x-foo", Globs: []sharedmime.Glob{{Pattern: "*.foo", Weight: 101}}},
+		{Type: "application/x-foo", Aliases: []string{"foo"}},
+		{Type: "application/x-foo", Parents: []string{"text/plain/x"}},
+	}
+	for _, def := range invalid {
+		require.Error(t, sharedmime.Register("xdg-test", def), def.Type)
+	}
+}
@@ -0,0 +1,269 @@
+package sharedmime
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/adrg/xdg/internal/locale"
+)
+
+// typeInfo contains the hierarchy and presentation data of the MIME types
+// of a database, read from the aliases, subclasses, icons and generic-icons
+// files. As MIME types are case-insensitive, the maps are indexed by the
+// lowercase MIME types, while the names map contains the spelling of the
+// MIME types, as defined in the database.
+type typeInfo struct {
+	names        map[string]string
+	aliases      map[string]string
+	parents      map[string][]string
+	icons        map[string]string
+	genericIcons map[string]string
+}
+
+// addName records the spelling of the specified MIME type.
+func (ti *typeInfo) addName(mimeType string) {
+	ti.names[strings.ToLower(mimeType)] = mimeType
+}
+
+// load loads the hierarchy and presentation data defined in the specified
+// mime directory. The data overrides the existing data, except for parent
+// types, which precede the existing ones.
+func (ti *typeInfo) load(dir string) error {
+	err := readPairs(filepath.Join(dir, "aliases"), " ", func(alias, mimeType string) {
+		ti.addName(mimeType)
+		ti.aliases[strings.ToLower(alias)] = mimeType
+	})
+	if err != nil {
+		return err
+	}
+
+	parents := map[string][]string{}
+	err = readPairs(filepath.Join(dir, "subclasses"), " ", func(mimeType, parent string) {
+		ti.addName(mimeType)
+		ti.addName(parent)
+
+		mimeType = strings.ToLower(mimeType)
+		parents[mimeType] = append(parents[mimeType], parent)
+	})
+	if err != nil {
+		return err
+	}
+	for mimeType, list := range parents {
+		for _, parent := range ti.parents[mimeType] {
+			if !slices.Contains(list, parent) {
+				list = append(list, parent)
+			}
+		}
+		ti.parents[mimeType] = list
+	}
+
+	err = readPairs(filepath.Join(dir, "icons"), ":", func(mimeType, icon string) {
+		ti.addName(mimeType)
+		ti.icons[strings.ToLower(mimeType)] = icon
+	})
+	if err != nil {
+		return err
+	}
+
+	return readPairs(filepath.Join(dir, "generic-icons"), ":", func(mimeType, icon string) {
+		ti.addName(mimeType)
+		ti.genericIcons[strings.ToLower(mimeType)] = icon
+	})
+}
+
+// readPairs calls the provided function for each pair of values of the
+// specified file, in which each line contains two values delimited by the
+// specified separator. Missing files, comments and malformed lines are
+// ignored.
+func readPairs(name, sep string, fn func(string, string)) error {
+	f, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		first, second, ok := strings.Cut(line, sep)
+		if first, second = strings.TrimSpace(first), strings.TrimSpace(second); ok && first != "" && second != "" {
+			fn(first, second)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// Canonical returns the canonical name of the specified MIME type, if the
+// MIME type is an alias (e.g. application/x-pdf is an alias of
+// application/pdf). MIME types are compared case-insensitively and are
+// returned as spelled in the database (e.g. Application/PDF resolves to
+// application/pdf). MIME types which are not defined in the database are
+// returned in lowercase.
+func (db *Database) Canonical(mimeType string) string {
+	mimeType = strings.ToLower(mimeType)
+	if canonical, ok := db.aliases[mimeType]; ok {
+		mimeType = strings.ToLower(canonical)
+	}
+	if name, ok := db.names[mimeType]; ok {
+		return name
+	}
+
+	return mimeType
+}
+
+// Parents returns the direct parents of the specified MIME type. Apart from
+// the parents defined in the database, all text/* MIME types are subclasses
+// of text/plain and all MIME types representing streams of bytes (i.e. all
+// MIME types except the inode/* ones) are subclasses of
+// application/octet-stream.
+func (db *Database) Parents(mimeType string) []string {
+	mimeType = db.Canonical(mimeType)
+	parents := slices.Clone(db.parents[strings.ToLower(mimeType)])
+
+	if strings.HasPrefix(mimeType, "text/") && mimeType != "text/plain" &&
+		!slices.Contains(parents, "text/plain") {
+		parents = append(parents, "text/plain")
+	}
+	if !strings.HasPrefix(mimeType, "inode/") && mimeType != "application/octet-stream" &&
+		!slices.Contains(parents, "application/octet-stream") {
+		parents = append(parents, "application/octet-stream")
+	}
+
+	return parents
+}
+
+// IsSubclassOf returns true if the specified MIME type is equal to or is
+// a subclass of the specified parent MIME type, either directly or through
+// other MIME types. Aliases are resolved before comparing MIME types.
+func (db *Database) IsSubclassOf(mimeType, parent string) bool {
+	mimeType, parent = db.Canonical(mimeType), db.Canonical(parent)
+
+	var (
+		queue = []string{mimeType}
+		seen  = map[string]bool{strings.ToLower(mimeType): true}
+	)
+	for len(queue) > 0 {
+		current := queue[0]
+		if queue = queue[1:]; strings.EqualFold(current, parent) {
+			return true
+		}
+
+		for _, p := range db.Parents(current) {
+			if p = db.Canonical(p); !seen[strings.ToLower(p)] {
+				seen[strings.ToLower(p)] = true
+				queue = append(queue, p)
+			}
+		}
+	}
+
+	return false
+}
+
+// Icon returns the name of the icon of the specified MIME type. If the
+// database does not define an icon for the MIME type, the icon name is
+// derived from the MIME type, by replacing the slash with a dash
+// (e.g. the icon of application/pdf is application-pdf).
+func (db *Database) Icon(mimeType string) string {
+	mimeType = db.Canonical(mimeType)
+	if icon, ok := db.icons[strings.ToLower(mimeType)]; ok {
+		return icon
+	}
+
+	return strings.ReplaceAll(mimeType, "/", "-")
+}
+
+// GenericIcon returns the name of the generic icon of the specified MIME
+// type, which should be used if the icon returned by Database.Icon is not
+// available. If the database does not define a generic icon for the MIME
+// type, the icon name is derived from the media type of the MIME type
+// (e.g. the generic icon of audio/ogg is audio-x-generic).
+func (db *Database) GenericIcon(mimeType string) string {
+	mimeType = db.Canonical(mimeType)
+	if icon, ok := db.genericIcons[strings.ToLower(mimeType)]; ok {
+		return icon
+	}
+
+	media, _, _ := strings.Cut(mimeType, "/")
+	return media + "-x-generic"
+}
+
+// Comment returns the human-readable description of the specified MIME
+// type (e.g. PNG image), read from the mime/<media>/<subtype>.xml file with
+// the highest precedence. The description is localized using the locale
+// defined by the LC_ALL, LC_MESSAGES and LANG environment variables.
+// If no localized description matches the locale, the non-localized
+// description is returned.
+func (db *Database) Comment(mimeType string) (string, error) {
+	mimeType = db.Canonical(mimeType)
+	media, subtype, ok := strings.Cut(mimeType, "/")
+	if !ok || media == "" || subtype == "" || media == ".." || subtype == ".." ||
+		strings.ContainsAny(mimeType[len(media)+1:], `/\`) || strings.Contains(media, `\`) {
+		return "", fmt.Errorf("invalid MIME type `%s`", mimeType)
+	}
+
+	for _, dir := range db.dirs {
+		comment, err := readComment(filepath.Join(dir, media, subtype+".xml"), locale.Messages())
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return "", err
+		}
+
+		return comment, nil
+	}
+
+	return "", fmt.Errorf("no description found for MIME type `%s`", mimeType)
+}
+
+// mimeTypeXML represents the content of the mime/<media>/<subtype>.xml files.
+type mimeTypeXML struct {
+	Comments []struct {
+		Lang string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+		Text string `xml:",chardata"`
+	} `xml:"comment"`
+}
+
+// readComment reads the description matching the specified locale from
+// the specified MIME type XML file.
+func readComment(name string, loc locale.Locale) (string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+
+	var info mimeTypeXML
+	if err := xml.Unmarshal(data, &info); err != nil {
+		return "", err
+	}
+
+	comments := make(map[string]string, len(info.Comments))
+	for _, comment := range info.Comments {
+		if _, ok := comments[comment.Lang]; !ok {
+			comments[comment.Lang] = strings.TrimSpace(comment.Text)
+		}
+	}
+	for _, candidate := range append(loc.Candidates(), "") {
+		if comment, ok := comments[candidate]; ok {
+			return comment, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s: missing comment", name)
+}
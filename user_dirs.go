@@ -0,0 +1,267 @@
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/adrg/xdg/internal/userdirs"
+	"github.com/adrg/xdg/internal/watch"
+)
+
+// UserDirsSyntaxError describes a malformed line of a user directories
+// config file, reported by ParseUserDirsStrict.
+type UserDirsSyntaxError = userdirs.SyntaxError
+
+// ParseUserDirs parses the user directories config file (user-dirs.dirs)
+// contained in the provided reader. Malformed lines are ignored, as done
+// when UserDirs is loaded.
+// The user directories config file is only used on Unix-like platforms, other
+// than macOS. On the rest of the platforms, errors.ErrUnsupported is returned.
+func ParseUserDirs(r io.Reader) (*UserDirectories, error) {
+	return parseUserDirs(r, false)
+}
+
+// ParseUserDirsStrict parses the user directories config file (user-dirs.dirs)
+// contained in the provided reader in strict mode. Apart from comments and
+// blank lines, the file must only contain lines in the XDG_xxx_DIR="$HOME/yyy"
+// format, where yyy is a shell-escaped path relative to the home directory,
+// or in the XDG_xxx_DIR="/yyy" format, where /yyy is a shell-escaped absolute
+// path. Each malformed line is reported as a *UserDirsSyntaxError, containing
+// the line number and the reason. The syntax errors are joined in the
+// returned error.
+// The user directories config file is only used on Unix-like platforms, other
+// than macOS. On the rest of the platforms, errors.ErrUnsupported is returned.
+func ParseUserDirsStrict(r io.Reader) (*UserDirectories, error) {
+	return parseUserDirs(r, true)
+}
+
+// SetUserDirs sets the locations of the specified user directories in the
+// user directories config file ($XDG_CONFIG_HOME/user-dirs.dirs), similar to
+// xdg-user-dirs-update. The keys of the dirs map must contain the names of
+// the user directories, as used in the config file (e.g. DOWNLOAD for the
+// XDG_DOWNLOAD_DIR entry), in any letter case, and the values must be
+// absolute paths. Custom user directories (e.g. PROJECTS) are also accepted.
+// The config file is created if it does not exist. Existing entries are
+// updated in place, while comments and unrelated entries are preserved. The
+// user directories are created if they do not exist and UserDirs is
+// refreshed afterwards.
+// The user directories config file is only used on Unix-like platforms, other
+// than macOS. On the rest of the platforms, errors.ErrUnsupported is returned.
+func SetUserDirs(dirs map[string]string) error {
+	return setUserDirs(dirs)
+}
+
+// EnsureUserDir returns the location of the user directory with the specified
+// name (e.g. DOWNLOAD or PROJECTS), in any letter case, creating it if it
+// does not exist. User directories which point to the home directory are
+// considered disabled, so they are not created. An error is returned if the
+// user directory is not defined.
+func EnsureUserDir(name string) (string, error) {
+	dir, ok := UserDirs.Lookup(name)
+	if !ok {
+		return "", fmt.Errorf("user directory `%s` is not defined", name)
+	}
+	if dir == Home {
+		return dir, nil
+	}
+	if err := os.MkdirAll(dir, os.ModeDir|0o755); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// EnsureUserDirs creates all the defined user directories which do not exist,
+// both well known and custom. On Unix-like platforms (other than macOS), the
+// user directories config file ($XDG_CONFIG_HOME/user-dirs.dirs) is also
+// written, along with the user-dirs.locale file, if it does not exist yet,
+// as done by xdg-user-dirs-update on the first login. Similar to
+// xdg-user-dirs-update, nothing is done if the management of the user
+// directories is disabled by the user-dirs.conf settings file.
+func EnsureUserDirs() error {
+	if !userDirsManaged() {
+		return nil
+	}
+
+	dirs := UserDirs.All()
+
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(dirs)) {
+		if _, err := EnsureUserDir(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 0 {
+		return errors.Join(errs...)
+	}
+
+	return provisionUserDirs(dirs)
+}
+
+// userDirsErrorsSize defines the number of errors buffered by a
+// UserDirsWatcher.
+const userDirsErrorsSize = 8
+
+// userDirsMu serializes the updates of UserDirs made by watchers.
+var userDirsMu sync.Mutex
+
+// UserDirsWatcher reloads the user directories when the user directories
+// config files change, updates UserDirs and notifies the registered
+// subscribers.
+type UserDirsWatcher struct {
+	watcher *watch.Watcher
+	load    func() UserDirectories
+
+	dirsMu sync.RWMutex
+	dirs   UserDirectories
+
+	subsMu sync.Mutex
+	subs   map[int]func(UserDirectories)
+	nextID int
+
+	errors chan error
+	done   chan struct{}
+	wg     sync.WaitGroup
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// WatchUserDirs watches the user directories config file
+// ($XDG_CONFIG_HOME/user-dirs.dirs), which is modified by applications such
+// as xdg-user-dirs-update, along with the user-dirs.locale, user-dirs.conf and
+// user-dirs.defaults files, which affect the locations of the user
+// directories. When any of the files is created, modified or removed, the
+// user directories are reloaded, UserDirs is updated and the subscribers of
+// the returned watcher are notified.
+// UserDirs is updated on the goroutine of the watcher, before the subscribers
+// are notified, so it is safe to read it from the subscriber callbacks and
+// after the watcher is closed. As UserDirs might be updated at any time while
+// the watcher is running, other goroutines should use the UserDirs method of
+// the watcher instead.
+// The user directories config file is only used on Unix-like platforms, other
+// than macOS. On the rest of the platforms, errors.ErrUnsupported is returned.
+func WatchUserDirs() (*UserDirsWatcher, error) {
+	paths := userDirsFiles()
+	if len(paths) == 0 {
+		return nil, errors.ErrUnsupported
+	}
+	_, load := userDirsConfig()
+
+	watcher, err := watch.New(paths, watchInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &UserDirsWatcher{
+		watcher: watcher,
+		load:    load,
+		dirs:    load(),
+		subs:    map[int]func(UserDirectories){},
+		errors:  make(chan error, userDirsErrorsSize),
+		done:    make(chan struct{}),
+	}
+
+	w.wg.Add(1)
+	go w.run()
+
+	return w, nil
+}
+
+// UserDirs returns the most recently loaded user directories. It is safe to
+// call UserDirs from multiple goroutines.
+func (w *UserDirsWatcher) UserDirs() UserDirectories {
+	w.dirsMu.RLock()
+	defer w.dirsMu.RUnlock()
+
+	return w.dirs
+}
+
+// Subscribe registers the specified function to be called with the updated
+// user directories, after they are reloaded. The returned function
+// unregisters the subscriber.
+func (w *UserDirsWatcher) Subscribe(fn func(dirs UserDirectories)) func() {
+	w.subsMu.Lock()
+	defer w.subsMu.Unlock()
+
+	id := w.nextID
+	w.subs[id] = fn
+	w.nextID++
+
+	return func() {
+		w.subsMu.Lock()
+		delete(w.subs, id)
+		w.subsMu.Unlock()
+	}
+}
+
+// Errors returns the channel on which watch errors are delivered. Receiving
+// from the channel is optional, as errors which occur while the channel is
+// full are discarded. The channel is closed when the watcher is closed.
+func (w *UserDirsWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Close stops the watcher. Subscribers are no longer notified after Close
+// returns.
+func (w *UserDirsWatcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		w.closeErr = w.watcher.Close()
+		w.wg.Wait()
+
+		close(w.errors)
+	})
+
+	return w.closeErr
+}
+
+func (w *UserDirsWatcher) run() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case err, ok := <-w.watcher.Errors():
+			if !ok {
+				return
+			}
+
+			select {
+			case w.errors <- err:
+			default:
+			}
+		case _, ok := <-w.watcher.Events():
+			if !ok {
+				return
+			}
+
+			dirs := w.load()
+
+			w.dirsMu.Lock()
+			w.dirs = dirs
+			w.dirsMu.Unlock()
+
+			userDirsMu.Lock()
+			UserDirs = dirs
+			userDirsMu.Unlock()
+
+			w.subsMu.Lock()
+			subs := make([]func(UserDirectories), 0, len(w.subs))
+			for _, fn := range w.subs {
+				subs = append(subs, fn)
+			}
+			w.subsMu.Unlock()
+
+			for _, fn := range subs {
+				fn(dirs)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+//go:build darwin || plan9 || windows
+
+package xdg
+
+import (
+	"errors"
+	"io"
+)
+
+func userDirsConfig() (string, func() UserDirectories) {
+	return "", nil
+}
+
+func userDirsFiles() []string {
+	return nil
+}
+
+func setUserDirs(map[string]string) error {
+	return errors.ErrUnsupported
+}
+
+func parseUserDirs(io.Reader, bool) (*UserDirectories, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func userDirsManaged() bool {
+	return true
+}
+
+func provisionUserDirs(map[string]string) error {
+	return nil
+}
@@ -0,0 +1,118 @@
+//go:build aix || dragonfly || freebsd || (js && wasm) || nacl || linux || netbsd || openbsd || solaris
+
+package xdg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrg/xdg/internal/pathutil"
+	"github.com/adrg/xdg/internal/userdirs"
+)
+
+func userDirsConfig() (string, func() UserDirectories) {
+	home, configHome, configDirs := Home, baseDirs.configHome, baseDirs.config
+	return filepath.Join(configHome, userdirs.ConfigFileName), func() UserDirectories {
+		return loadUserDirs(home, configHome, configDirs)
+	}
+}
+
+// userDirsFiles returns the locations of the files which affect the user
+// directories.
+func userDirsFiles() []string {
+	configHome := baseDirs.configHome
+
+	paths := []string{
+		filepath.Join(configHome, userdirs.ConfigFileName),
+		filepath.Join(configHome, userdirs.LocaleFileName),
+		filepath.Join(configHome, userdirs.SettingsFileName),
+	}
+	for _, dir := range baseDirs.config {
+		paths = append(paths,
+			filepath.Join(dir, userdirs.DefaultsFileName),
+			filepath.Join(dir, userdirs.SettingsFileName),
+		)
+	}
+
+	return pathutil.Unique(paths)
+}
+
+func setUserDirs(dirs map[string]string) error {
+	entries := make(map[string]string, len(dirs))
+	for name, dir := range dirs {
+		if name = strings.ToUpper(name); !validUserDirName(name) {
+			return fmt.Errorf("invalid user directory name `%s`", name)
+		}
+		if dir = pathutil.ExpandHome(dir); !filepath.IsAbs(dir) {
+			return fmt.Errorf("user directory `%s` must be an absolute path: %s", name, dir)
+		}
+		if err := os.MkdirAll(dir, os.ModeDir|0o755); err != nil {
+			return err
+		}
+
+		entries[userdirs.EnvName(name)] = dir
+	}
+
+	if err := os.MkdirAll(baseDirs.configHome, os.ModeDir|0o700); err != nil {
+		return err
+	}
+
+	path, load := userDirsConfig()
+	if err := userdirs.UpdateConfigFile(path, entries); err != nil {
+		return err
+	}
+	UserDirs = load()
+
+	return nil
+}
+
+func validUserDirName(name string) bool {
+	return name != "" && strings.Trim(name, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_") == ""
+}
+
+func parseUserDirs(r io.Reader, strict bool) (*UserDirectories, error) {
+	if strict {
+		return userdirs.ParseConfigStrict(r)
+	}
+
+	return userdirs.ParseConfig(r)
+}
+
+func userDirsManaged() bool {
+	return userDirsEnabled(baseDirs.configHome, baseDirs.config)
+}
+
+func provisionUserDirs(dirs map[string]string) error {
+	configHome := baseDirs.configHome
+
+	path, load := userDirsConfig()
+	if pathutil.Exists(path) {
+		return nil
+	}
+
+	entries := make(map[string]string, len(dirs))
+	for name, dir := range dirs {
+		entries[userdirs.EnvName(name)] = dir
+	}
+
+	if err := os.MkdirAll(configHome, os.ModeDir|0o700); err != nil {
+		return err
+	}
+	if err := userdirs.UpdateConfigFile(path, entries); err != nil {
+		return err
+	}
+
+	// Record the locale used for translating the user directory names.
+	if l := userDirsLocale(configHome); !l.IsZero() {
+		localePath := filepath.Join(configHome, userdirs.LocaleFileName)
+		if err := os.WriteFile(localePath, []byte(l.String()+"\n"), 0o644); err != nil {
+			return err
+		}
+	}
+	UserDirs = load()
+
+	return nil
+}
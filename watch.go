@@ -0,0 +1,189 @@
+package xdg
+
+import (
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg/internal/watch"
+)
+
+// watchInterval defines the period at which watched files are checked for
+// changes, on platforms which do not support file system notifications.
+const watchInterval = 2 * time.Second
+
+// ConfigOp describes a change of a watched config file.
+type ConfigOp int
+
+// Watched config file changes.
+const (
+	// ConfigCreate indicates that a config file was created in one of the
+	// search paths, while no config file existed previously.
+	ConfigCreate ConfigOp = iota + 1
+
+	// ConfigModify indicates that the effective config file was modified or
+	// replaced.
+	ConfigModify
+
+	// ConfigChange indicates that the effective config file changed to a file
+	// in a different search path (e.g. a config file with a higher precedence
+	// was created, or the effective one was removed and a config file with a
+	// lower precedence is used instead).
+	ConfigChange
+
+	// ConfigRemove indicates that the effective config file was removed and
+	// no config file exists in any of the search paths.
+	ConfigRemove
+)
+
+// String returns a textual representation of the config file change.
+func (op ConfigOp) String() string {
+	switch op {
+	case ConfigCreate:
+		return "CREATE"
+	case ConfigModify:
+		return "MODIFY"
+	case ConfigChange:
+		return "CHANGE"
+	case ConfigRemove:
+		return "REMOVE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ConfigEvent describes a change of a watched config file.
+type ConfigEvent struct {
+	// Op describes the change of the config file.
+	Op ConfigOp
+
+	// Path contains the location of the effective config file, after the
+	// change. It is empty if no config file exists.
+	Path string
+
+	// PrevPath contains the location of the effective config file, before
+	// the change. It is empty if no config file existed.
+	PrevPath string
+}
+
+// ConfigWatcher reports changes of the effective location and of the contents
+// of a config file, across all config search paths.
+type ConfigWatcher struct {
+	watcher *watch.Watcher
+	paths   []string
+	path    string
+
+	events chan ConfigEvent
+	done   chan struct{}
+	wg     sync.WaitGroup
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// WatchConfigFile watches the specified config file in the config search
+// paths (ConfigHome and ConfigDirs). The relPath parameter must contain the
+// name of the config file, and optionally, a set of parent directories
+// (e.g. appname/app.yaml). The effective config file is the one which would
+// be returned by SearchConfigFile. An event is delivered whenever the
+// effective config file is created, modified, removed or superseded by a
+// config file in a search path with a different precedence. The config file
+// and its parent directories are not required to exist.
+// On Linux, changes are detected using inotify. On other platforms, the
+// search paths are checked periodically.
+func WatchConfigFile(relPath string) (*ConfigWatcher, error) {
+	return baseDirs.watchConfigFile(relPath)
+}
+
+func newConfigWatcher(paths []string) (*ConfigWatcher, error) {
+	watcher, err := watch.New(paths, watchInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &ConfigWatcher{
+		watcher: watcher,
+		paths:   paths,
+		events:  make(chan ConfigEvent),
+		done:    make(chan struct{}),
+	}
+	w.path = w.effectivePath()
+
+	w.wg.Add(1)
+	go w.run()
+
+	return w, nil
+}
+
+// Events returns the channel on which config file changes are delivered.
+// The channel is closed when the watcher is closed.
+func (w *ConfigWatcher) Events() <-chan ConfigEvent {
+	return w.events
+}
+
+// Errors returns the channel on which watch errors are delivered. Errors must
+// be received from the channel in order for the watcher to make progress.
+// The channel is closed when the watcher is closed.
+func (w *ConfigWatcher) Errors() <-chan error {
+	return w.watcher.Errors()
+}
+
+// Close stops the watcher and closes its channels.
+func (w *ConfigWatcher) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		w.closeErr = w.watcher.Close()
+		w.wg.Wait()
+
+		close(w.events)
+	})
+
+	return w.closeErr
+}
+
+func (w *ConfigWatcher) run() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.watcher.Events():
+			if !ok {
+				return
+			}
+
+			prevPath := w.path
+			w.path = w.effectivePath()
+
+			var op ConfigOp
+			switch {
+			case prevPath == "" && w.path != "":
+				op = ConfigCreate
+			case prevPath != "" && w.path == "":
+				op = ConfigRemove
+			case prevPath != w.path:
+				op = ConfigChange
+			case event.Path == w.path && event.Op == watch.Modify:
+				op = ConfigModify
+			default:
+				continue
+			}
+
+			select {
+			case w.events <- ConfigEvent{Op: op, Path: w.path, PrevPath: prevPath}:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+func (w *ConfigWatcher) effectivePath() string {
+	for _, p := range w.paths {
+		if w.watcher.Exists(p) {
+			return p
+		}
+	}
+
+	return ""
+}
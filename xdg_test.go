@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -230,3 +231,143 @@ func TestNonExistentRuntimeDir(t *testing.T) {
 
 	require.NoError(t, os.Setenv(envRuntimeDirVar, originalRuntimeDir))
 }
+
+func TestLogFile(t *testing.T) {
+	t.Cleanup(xdg.Reload)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	xdg.Reload()
+
+	// Test default options.
+	w, err := xdg.LogFile("appname/app.log", nil)
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("message\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	logPath, err := xdg.SearchStateFile("appname/app.log")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(xdg.StateHome, "appname", "app.log"), logPath)
+
+	// Test custom options.
+	w, err = xdg.LogFile("appname/app.log", &xdg.LogOptions{MaxSize: 8, MaxBackups: 1})
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("message\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	entries, err := os.ReadDir(filepath.Dir(logPath))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// Test invalid paths.
+	_, err = xdg.LogFile("appname/\000/app.log", nil)
+	require.Error(t, err)
+}
+
+func TestHistoryFile(t *testing.T) {
+	t.Cleanup(xdg.Reload)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	xdg.Reload()
+
+	// Test default options.
+	h, err := xdg.HistoryFile("appname/history", nil)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(xdg.StateHome, "appname", "history"), h.Name())
+
+	require.NoError(t, h.Append("ls", "pwd", "ls"))
+
+	entries, err := h.Entries()
+	require.NoError(t, err)
+	require.Equal(t, []string{"pwd", "ls"}, entries)
+
+	// Test custom options.
+	h, err = xdg.HistoryFile("appname/history", &xdg.HistoryOptions{MaxEntries: 1})
+	require.NoError(t, err)
+	require.NoError(t, h.Append("cd"))
+
+	entries, err = h.Entries()
+	require.NoError(t, err)
+	require.Equal(t, []string{"cd"}, entries)
+
+	// Test invalid paths.
+	_, err = xdg.HistoryFile("appname/\000/history", nil)
+	require.Error(t, err)
+}
+
+func TestWatchConfigFile(t *testing.T) {
+	var (
+		configHome = t.TempDir()
+		configDir  = t.TempDir()
+		homePath   = filepath.Join(configHome, "appname", "app.yaml")
+		dirPath    = filepath.Join(configDir, "appname", "app.yaml")
+	)
+
+	t.Cleanup(xdg.Reload)
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_CONFIG_DIRS", configDir)
+	xdg.Reload()
+
+	w, err := xdg.WatchConfigFile("appname/app.yaml")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, w.Close())
+	}()
+
+	receiveEvent := func() xdg.ConfigEvent {
+		select {
+		case event := <-w.Events():
+			return event
+		case err := <-w.Errors():
+			require.NoError(t, err)
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "timed out waiting for event")
+		}
+		return xdg.ConfigEvent{}
+	}
+
+	// Test creation of the config file with the lowest precedence.
+	require.NoError(t, os.MkdirAll(filepath.Dir(dirPath), 0o700))
+	require.NoError(t, os.WriteFile(dirPath, []byte("a: 1"), 0o600))
+	require.Equal(t, xdg.ConfigEvent{Op: xdg.ConfigCreate, Path: dirPath}, receiveEvent())
+
+	// Test creation of the config file with the highest precedence.
+	require.NoError(t, os.MkdirAll(filepath.Dir(homePath), 0o700))
+	require.NoError(t, os.WriteFile(homePath, []byte("a: 2"), 0o600))
+	require.Equal(t, xdg.ConfigEvent{Op: xdg.ConfigChange, Path: homePath, PrevPath: dirPath}, receiveEvent())
+
+	// Test modification of the effective config file.
+	require.NoError(t, os.WriteFile(homePath, []byte("a: 20"), 0o600))
+	require.Equal(t, xdg.ConfigEvent{Op: xdg.ConfigModify, Path: homePath, PrevPath: homePath}, receiveEvent())
+
+	// Test removal of the effective config file.
+	require.NoError(t, os.Remove(homePath))
+	require.Equal(t, xdg.ConfigEvent{Op: xdg.ConfigChange, Path: dirPath, PrevPath: homePath}, receiveEvent())
+
+	require.NoError(t, os.Remove(dirPath))
+	require.Equal(t, xdg.ConfigEvent{Op: xdg.ConfigRemove, PrevPath: dirPath}, receiveEvent())
+	require.Equal(t, "REMOVE", xdg.ConfigRemove.String())
+}
+
+func TestEnsureUserDir(t *testing.T) {
+	downloadDir := filepath.Join(t.TempDir(), "downloads")
+
+	t.Cleanup(xdg.Reload)
+	t.Setenv("XDG_DOWNLOAD_DIR", downloadDir)
+	xdg.Reload()
+
+	dir, err := xdg.EnsureUserDir("download")
+	require.NoError(t, err)
+	require.Equal(t, downloadDir, dir)
+	require.DirExists(t, downloadDir)
+
+	// Test existing user directory.
+	dir, err = xdg.EnsureUserDir("DOWNLOAD")
+	require.NoError(t, err)
+	require.Equal(t, downloadDir, dir)
+
+	// Test undefined user directory.
+	_, err = xdg.EnsureUserDir("UNDEFINED")
+	require.Error(t, err)
+}